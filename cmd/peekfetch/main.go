@@ -1,16 +1,71 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"os"
 
+	"peekfetch/internal/config"
+	"peekfetch/internal/export"
+	"peekfetch/internal/sysinfo"
 	"peekfetch/internal/ui"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-isatty"
 )
 
 func main() {
-	p := tea.NewProgram(ui.InitialModel(), tea.WithAltScreen())
+	once := flag.Bool("once", false, "print a one-shot summary of every section and exit")
+	asJSON := flag.Bool("json", false, "print every section as JSON and exit")
+	theme := flag.String("theme", "", "color theme: auto, dark, light, high-contrast, colorblind, monochrome or a custom theme name")
+	root := flag.String("root", os.Getenv("PEEKFETCH_ROOT"), "read /proc, /sys and /etc below this directory, e.g. a host mounted into a container (env PEEKFETCH_ROOT)")
+	configPath := flag.String("config", "", "path to the config file (default $XDG_CONFIG_HOME/peekfetch/config.toml)")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	registry := sysinfo.DefaultRegistry()
+	if err := cfg.Apply(registry); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *root != "" {
+		if err := registry.SetRoot(*root); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *asJSON {
+		out, err := export.JSON(registry.Snapshot(context.Background()))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(out))
+		return
+	}
+
+	if err := cfg.ApplyTheme(*theme); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *once {
+		if !isatty.IsTerminal(os.Stdout.Fd()) {
+			ui.UsePlainText()
+		}
+		fmt.Print(ui.RenderOnce(registry.Snapshot(context.Background()), registry.OSRelease()))
+		return
+	}
+
+	opts := ui.Options{LiveMode: cfg.Live, Expanded: cfg.Expanded, HistorySize: cfg.History}
+	p := tea.NewProgram(ui.InitialModel(registry, opts), tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
@@ -0,0 +1,278 @@
+// Package config loads user preferences from
+// $XDG_CONFIG_HOME/peekfetch/config.toml.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"peekfetch/internal/sysinfo"
+)
+
+// Config holds every user preference. The zero value keeps the built-in
+// defaults.
+type Config struct {
+	Path            string        // File the config was loaded from, if any
+	Live            bool          // Start in live mode
+	Theme           string        // Built-in or custom theme name; empty selects automatically
+	RefreshInterval time.Duration // Live refresh interval; zero keeps the collector defaults
+	History         int           // Live samples kept per field for sparklines; zero keeps the default
+	Order           []string      // Section display order
+	Sections        map[string]SectionConfig
+	Disk            DiskConfig
+	orderLine       int
+}
+
+// SectionConfig holds the preferences for a single section
+type SectionConfig struct {
+	Enabled         bool
+	Expanded        bool
+	Fields          []string      // Fields to show, in order; empty shows every field
+	RefreshInterval time.Duration // Overrides the global refresh interval
+	line            int
+}
+
+// DiskConfig holds the Disk section preferences
+type DiskConfig struct {
+	HiddenFilesystems []string // Filesystem types to skip; nil keeps the defaults
+}
+
+// DefaultPath returns the default config file location
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "peekfetch", "config.toml"), nil
+}
+
+// Load reads the config file at path. An empty path loads the default
+// location, where a missing file is not an error.
+func Load(path string) (*Config, error) {
+	explicit := path != ""
+	if !explicit {
+		var err error
+		if path, err = DefaultPath(); err != nil {
+			return &Config{}, nil
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return &Config{}, nil
+		}
+		return nil, err
+	}
+
+	cfg, err := Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	cfg.Path = path
+	return cfg, nil
+}
+
+// Parse decodes a config document, rejecting unknown keys
+func Parse(data string) (*Config, error) {
+	doc, err := parseTOML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{Sections: make(map[string]SectionConfig)}
+
+	for _, t := range doc.Tables {
+		parts := t.Path
+		switch {
+		case t.Key == "sections" || t.Key == "disk":
+		case len(parts) == 2 && parts[0] == "sections":
+			cfg.section(parts[1], t.Line)
+		default:
+			return nil, fmt.Errorf("line %d: unknown table [%s]", t.Line, t.Key)
+		}
+	}
+
+	for _, e := range doc.Entries {
+		if err := cfg.set(e); err != nil {
+			return nil, fmt.Errorf("line %d: %v", e.Line, err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// section returns the named section config, creating it enabled
+func (c *Config) section(name string, line int) SectionConfig {
+	sc, ok := c.Sections[name]
+	if !ok {
+		sc = SectionConfig{Enabled: true, line: line}
+		c.Sections[name] = sc
+	}
+	return sc
+}
+
+func (c *Config) set(e entry) error {
+	parts := e.Path
+
+	switch {
+	case e.Key == "live":
+		return setBool(&c.Live, e)
+	case e.Key == "theme":
+		return setString(&c.Theme, e)
+	case e.Key == "refresh_interval":
+		return setDuration(&c.RefreshInterval, e)
+	case e.Key == "history":
+		return setInt(&c.History, e, 2, 10000)
+	case e.Key == "order":
+		c.orderLine = e.Line
+		return setStrings(&c.Order, e)
+	case e.Key == "disk.hidden_filesystems":
+		return setStrings(&c.Disk.HiddenFilesystems, e)
+	case len(parts) == 3 && parts[0] == "sections":
+		sc := c.section(parts[1], e.Line)
+		var err error
+		switch parts[2] {
+		case "enabled":
+			err = setBool(&sc.Enabled, e)
+		case "expanded":
+			err = setBool(&sc.Expanded, e)
+		case "fields":
+			err = setStrings(&sc.Fields, e)
+		case "refresh_interval":
+			err = setDuration(&sc.RefreshInterval, e)
+		default:
+			return fmt.Errorf("unknown key %q in [sections.%s]", parts[2], parts[1])
+		}
+		c.Sections[parts[1]] = sc
+		return err
+	}
+
+	if len(parts) > 1 {
+		return fmt.Errorf("unknown key %q in [%s]", parts[len(parts)-1], formatKey(parts[:len(parts)-1]))
+	}
+	return fmt.Errorf("unknown key %q", e.Key)
+}
+
+func setBool(dst *bool, e entry) error {
+	v, ok := e.Value.(bool)
+	if !ok {
+		return fmt.Errorf("%s must be true or false", e.Key)
+	}
+	*dst = v
+	return nil
+}
+
+func setString(dst *string, e entry) error {
+	v, ok := e.Value.(string)
+	if !ok {
+		return fmt.Errorf("%s must be a string", e.Key)
+	}
+	*dst = v
+	return nil
+}
+
+func setInt(dst *int, e entry, lo, hi int64) error {
+	v, ok := e.Value.(int64)
+	if !ok {
+		return fmt.Errorf("%s must be an integer", e.Key)
+	}
+	if v < lo || v > hi {
+		return fmt.Errorf("%s must be between %d and %d", e.Key, lo, hi)
+	}
+	*dst = int(v)
+	return nil
+}
+
+func setStrings(dst *[]string, e entry) error {
+	v, ok := e.Value.([]string)
+	if !ok {
+		return fmt.Errorf("%s must be an array of strings", e.Key)
+	}
+	*dst = v
+	return nil
+}
+
+func setDuration(dst *time.Duration, e entry) error {
+	s, ok := e.Value.(string)
+	if !ok {
+		return fmt.Errorf("%s must be a duration string such as \"500ms\" or \"2s\"", e.Key)
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return fmt.Errorf("%s: invalid duration %q", e.Key, s)
+	}
+	*dst = d
+	return nil
+}
+
+// Apply configures the registry's collectors: hidden filesystems, fields,
+// refresh intervals, disabled sections and order. Section names are
+// matched case-insensitively.
+func (c *Config) Apply(r *sysinfo.Registry) error {
+	if c.Disk.HiddenFilesystems != nil {
+		r.Register(sysinfo.NewDiskCollector(c.Disk.HiddenFilesystems))
+	}
+
+	names := r.Names()
+	for _, name := range names {
+		if c.RefreshInterval > 0 {
+			r.Configure(name, sysinfo.Override{Interval: c.RefreshInterval})
+		}
+	}
+
+	for key, sc := range c.Sections {
+		name, ok := matchName(names, key)
+		if !ok {
+			return c.errorf(sc.line, "unknown section %q (available: %s)", key, strings.Join(names, ", "))
+		}
+		r.Configure(name, sysinfo.Override{Fields: sc.Fields, Interval: sc.RefreshInterval})
+		if !sc.Enabled {
+			r.Disable(name)
+		}
+	}
+
+	order := make([]string, 0, len(c.Order))
+	for _, key := range c.Order {
+		name, ok := matchName(names, key)
+		if !ok {
+			return c.errorf(c.orderLine, "unknown section %q in order (available: %s)", key, strings.Join(names, ", "))
+		}
+		order = append(order, name)
+	}
+	r.Reorder(order)
+
+	return nil
+}
+
+// Expanded reports whether the named section should start expanded
+func (c *Config) Expanded(name string) bool {
+	for key, sc := range c.Sections {
+		if strings.EqualFold(key, name) {
+			return sc.Expanded
+		}
+	}
+	return false
+}
+
+func (c *Config) errorf(line int, format string, args ...any) error {
+	msg := fmt.Sprintf(format, args...)
+	if c.Path != "" {
+		return fmt.Errorf("%s: line %d: %s", c.Path, line, msg)
+	}
+	return fmt.Errorf("line %d: %s", line, msg)
+}
+
+func matchName(names []string, key string) (string, bool) {
+	for _, name := range names {
+		if strings.EqualFold(name, key) {
+			return name, true
+		}
+	}
+	return "", false
+}
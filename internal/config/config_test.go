@@ -0,0 +1,159 @@
+package config
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"peekfetch/internal/sysinfo"
+	"peekfetch/internal/types"
+)
+
+func TestParse(t *testing.T) {
+	cfg, err := Parse(`
+live = true
+refresh_interval = "750ms"
+order = ["memory", "CPU"]
+
+[sections.CPU]
+expanded = true
+fields = ["Model", "Cores"]
+refresh_interval = "2s"
+
+[sections.Disk]
+enabled = false
+
+[sections."Odd.Name"]
+expanded = true
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cfg.Live || cfg.RefreshInterval != 750*time.Millisecond {
+		t.Errorf("live = %v, refresh_interval = %v", cfg.Live, cfg.RefreshInterval)
+	}
+	if !slices.Equal(cfg.Order, []string{"memory", "CPU"}) {
+		t.Errorf("order = %q", cfg.Order)
+	}
+	cpu := cfg.Sections["CPU"]
+	if !cpu.Enabled || !cpu.Expanded || cpu.RefreshInterval != 2*time.Second || !slices.Equal(cpu.Fields, []string{"Model", "Cores"}) {
+		t.Errorf("sections.CPU = %+v", cpu)
+	}
+	if disk := cfg.Sections["Disk"]; disk.Enabled || disk.Expanded {
+		t.Errorf("sections.Disk = %+v, want disabled", disk)
+	}
+	if odd, ok := cfg.Sections["Odd.Name"]; !ok || !odd.Expanded {
+		t.Errorf("Sections = %+v, want a quoted name kept whole", cfg.Sections)
+	}
+	if !cfg.Expanded("cpu") || cfg.Expanded("Disk") {
+		t.Errorf("Expanded does not follow the sections")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		doc  string
+		want string
+	}{
+		{"colour = \"red\"", `line 1: unknown key "colour"`},
+		{"\n[display]\n", "line 2: unknown table [display]"},
+		{"[sections.CPU]\nenabled = true\nvisible = false", `line 3: unknown key "visible" in [sections.CPU]`},
+		{"[sections.CPU.extra]", "line 1: unknown table [sections.CPU.extra]"},
+		{"[disk]\nhidden = []", `line 2: unknown key "hidden" in [disk]`},
+		{"\"a.b\" = 1", `line 1: unknown key "\"a.b\""`},
+		{"live = \"yes\"", "line 1: live must be true or false"},
+		{"refresh_interval = 500", `line 1: refresh_interval must be a duration string`},
+		{"refresh_interval = \"soon\"", `line 1: refresh_interval: invalid duration "soon"`},
+		{"refresh_interval = \"0s\"", `line 1: refresh_interval: invalid duration "0s"`},
+		{"[sections.CPU]\nrefresh_interval = \"-1s\"", `line 2: sections.CPU.refresh_interval: invalid duration "-1s"`},
+		{"order = \"CPU\"", "line 1: order must be an array of strings"},
+		{"history = 1", "line 1: history must be between 2 and 10000"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.doc)
+		if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("Parse(%q) = %v, want %q", tt.doc, err, tt.want)
+		}
+	}
+}
+
+// stubCollector is a live section with three fields
+type stubCollector struct{ name string }
+
+func (c stubCollector) Name() string            { return c.name }
+func (c stubCollector) Interval() time.Duration { return sysinfo.DefaultInterval }
+
+func (c stubCollector) Collect(ctx context.Context) types.Section {
+	return types.Section{
+		Name:  c.name,
+		Data:  map[string]types.Field{"A": types.Count(1), "B": types.Count(2), "C": types.Count(3)},
+		Order: []string{"A", "B", "C"},
+	}
+}
+
+func (c stubCollector) Refresh(ctx context.Context, section types.Section) types.Section {
+	return section
+}
+
+func stubRegistry() *sysinfo.Registry {
+	return sysinfo.NewRegistry(stubCollector{"CPU"}, stubCollector{"Memory"}, stubCollector{"Disk"})
+}
+
+func TestApply(t *testing.T) {
+	cfg, err := Parse(`
+refresh_interval = "750ms"
+order = ["disk", "cpu"]
+
+[sections.cpu]
+fields = ["C", "A"]
+refresh_interval = "2s"
+
+[sections.Memory]
+enabled = false
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := stubRegistry()
+	if err := cfg.Apply(r); err != nil {
+		t.Fatal(err)
+	}
+
+	if names := r.Names(); !slices.Equal(names, []string{"Disk", "CPU"}) {
+		t.Errorf("Names = %q, want Memory disabled and Disk first", names)
+	}
+	intervals := map[string]time.Duration{"CPU": 2 * time.Second, "Disk": 750 * time.Millisecond}
+	for name, want := range intervals {
+		c, _ := r.Lookup(name)
+		if got := c.(sysinfo.LiveCollector).Interval(); got != want {
+			t.Errorf("%s interval = %v, want %v", name, got, want)
+		}
+	}
+	c, _ := r.Lookup("CPU")
+	if order := r.Collect(context.Background(), c).Order; !slices.Equal(order, []string{"C", "A"}) {
+		t.Errorf("CPU fields = %q, want C and A", order)
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	tests := []struct {
+		doc  string
+		want string
+	}{
+		{"[sections.Network]\nenabled = false", `line 1: unknown section "Network" (available: CPU, Memory, Disk)`},
+		{"\norder = [\"CPU\", \"GPU\"]", `line 2: unknown section "GPU" in order`},
+	}
+	for _, tt := range tests {
+		cfg, err := Parse(tt.doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = cfg.Apply(stubRegistry())
+		if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("Apply(%q) = %v, want %q", tt.doc, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"peekfetch/internal/ui"
+
+	"github.com/muesli/termenv"
+)
+
+// ThemeAuto selects the dark or light theme from the terminal background
+const ThemeAuto = "auto"
+
+// ThemeDir returns the directory custom themes are loaded from: a themes
+// directory next to the config file
+func (c *Config) ThemeDir() (string, error) {
+	if c.Path != "" {
+		return filepath.Join(filepath.Dir(c.Path), "themes"), nil
+	}
+	path, err := DefaultPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "themes"), nil
+}
+
+// ApplyTheme switches the UI to the named theme, falling back to the
+// config file's theme when name is empty. Without an explicit theme,
+// NO_COLOR selects the monochrome theme and otherwise the theme follows
+// the terminal background. An explicit theme wins over NO_COLOR.
+func (c *Config) ApplyTheme(name string) error {
+	if name == "" {
+		name = c.Theme
+	}
+
+	if name == "" || strings.EqualFold(name, ThemeAuto) {
+		if termenv.EnvNoColor() {
+			ui.UseNoColor()
+		} else {
+			ui.ApplyTheme(ui.AutoTheme())
+		}
+		return nil
+	}
+
+	theme, err := c.LoadTheme(name)
+	if err != nil {
+		return err
+	}
+	ui.ApplyTheme(theme)
+	if termenv.EnvNoColor() {
+		ui.ForceColor()
+	}
+	return nil
+}
+
+// LoadTheme returns the named built-in theme, or reads
+// <ThemeDir>/<name>.toml. A theme file sets colors on top of a base theme:
+//
+//	base = "dark"
+//
+//	[colors]
+//	primary = "#FF8800"
+//	muted = "244"
+func (c *Config) LoadTheme(name string) (ui.Theme, error) {
+	if theme, ok := ui.ThemeByName(name); ok {
+		return theme, nil
+	}
+
+	dir, err := c.ThemeDir()
+	if err != nil {
+		return ui.Theme{}, err
+	}
+	path := filepath.Join(dir, name+".toml")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return ui.Theme{}, fmt.Errorf("unknown theme %q (built-in: %s, %s; custom themes go in %s)", name, ThemeAuto, strings.Join(themeNames(), ", "), dir)
+		}
+		return ui.Theme{}, err
+	}
+
+	theme, err := parseTheme(name, string(data))
+	if err != nil {
+		return ui.Theme{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return theme, nil
+}
+
+// parseTheme decodes a theme file, rejecting unknown keys and colors
+func parseTheme(name, data string) (ui.Theme, error) {
+	doc, err := parseTOML(data)
+	if err != nil {
+		return ui.Theme{}, err
+	}
+
+	for _, t := range doc.Tables {
+		if t.Key != "colors" {
+			return ui.Theme{}, fmt.Errorf("line %d: unknown table [%s]", t.Line, t.Key)
+		}
+	}
+
+	// The base has to be known before any color is layered on it
+	theme := ui.DarkTheme
+	for _, e := range doc.Entries {
+		if e.Key != "base" {
+			continue
+		}
+		var base string
+		if err := setString(&base, e); err != nil {
+			return ui.Theme{}, fmt.Errorf("line %d: %v", e.Line, err)
+		}
+		var ok bool
+		if theme, ok = ui.ThemeByName(base); !ok {
+			return ui.Theme{}, fmt.Errorf("line %d: unknown base theme %q (available: %s)", e.Line, base, strings.Join(themeNames(), ", "))
+		}
+	}
+	theme.Name = name
+
+	for _, e := range doc.Entries {
+		color, ok := strings.CutPrefix(e.Key, "colors.")
+		switch {
+		case e.Key == "base":
+			continue
+		case !ok:
+			return ui.Theme{}, fmt.Errorf("line %d: unknown key %q", e.Line, e.Key)
+		case !slices.Contains(ui.ThemeColorNames, color):
+			return ui.Theme{}, fmt.Errorf("line %d: unknown color %q (available: %s)", e.Line, color, strings.Join(ui.ThemeColorNames, ", "))
+		}
+
+		var value string
+		if err := setString(&value, e); err != nil {
+			return ui.Theme{}, fmt.Errorf("line %d: %v", e.Line, err)
+		}
+		if theme, err = theme.WithColors(name, map[string]string{color: value}); err != nil {
+			return ui.Theme{}, fmt.Errorf("line %d: %v", e.Line, err)
+		}
+	}
+
+	return theme, nil
+}
+
+func themeNames() []string {
+	names := make([]string, 0, len(ui.Themes))
+	for _, t := range ui.Themes {
+		names = append(names, t.Name)
+	}
+	return names
+}
@@ -0,0 +1,319 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// entry is a single key/value pair from a TOML document. Path holds the
+// segments of the full key including the enclosing table, e.g. sections,
+// CPU and fields; Key joins them with dots for messages.
+type entry struct {
+	Key   string
+	Path  []string
+	Value any // string, int64, float64, bool or []string
+	Line  int
+}
+
+// table is a [table] header from a TOML document
+type table struct {
+	Key  string
+	Path []string
+	Line int
+}
+
+// document is a parsed TOML document, with entries in file order
+type document struct {
+	Tables  []table
+	Entries []entry
+}
+
+// parseTOML parses the subset of TOML that the config file uses: tables,
+// comments, and keys holding basic or literal strings, integers, floats,
+// booleans or arrays of strings. Arrays may span multiple lines; strings
+// may not.
+func parseTOML(data string) (document, error) {
+	var doc document
+	var prefix []string
+	seen := make(map[string]int)
+
+	lines := strings.Split(data, "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimSpace(stripComment(lines[i]))
+		if line == "" {
+			continue
+		}
+
+		// Table header
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || strings.HasPrefix(line, "[[") {
+				return doc, fmt.Errorf("line %d: invalid table header %q", lineNo, line)
+			}
+			path, err := parseKey(strings.TrimSpace(line[1 : len(line)-1]))
+			if err != nil {
+				return doc, fmt.Errorf("line %d: %v", lineNo, err)
+			}
+			key := formatKey(path)
+			if prev, ok := seen["["+key+"]"]; ok {
+				return doc, fmt.Errorf("line %d: table [%s] already defined on line %d", lineNo, key, prev)
+			}
+			seen["["+key+"]"] = lineNo
+			doc.Tables = append(doc.Tables, table{Key: key, Path: path, Line: lineNo})
+			prefix = path
+			continue
+		}
+
+		// Key/value pair
+		eq := indexUnquoted(line, '=')
+		if eq < 0 {
+			return doc, fmt.Errorf("line %d: expected key = value", lineNo)
+		}
+		key, err := parseKey(strings.TrimSpace(line[:eq]))
+		if err != nil {
+			return doc, fmt.Errorf("line %d: %v", lineNo, err)
+		}
+		raw := strings.TrimSpace(line[eq+1:])
+
+		// Join the continuation lines of a multi-line array
+		if strings.HasPrefix(raw, "[") {
+			for !arrayClosed(raw) && i+1 < len(lines) {
+				i++
+				raw += " " + strings.TrimSpace(stripComment(lines[i]))
+			}
+		}
+
+		value, err := parseValue(raw)
+		if err != nil {
+			return doc, fmt.Errorf("line %d: %v", lineNo, err)
+		}
+
+		path := append(slices.Clip(prefix), key...)
+		full := formatKey(path)
+		if prev, ok := seen[full]; ok {
+			return doc, fmt.Errorf("line %d: key %q already defined on line %d", lineNo, full, prev)
+		}
+		seen[full] = lineNo
+		doc.Entries = append(doc.Entries, entry{Key: full, Path: path, Value: value, Line: lineNo})
+	}
+
+	return doc, nil
+}
+
+// indexUnquoted returns the index of the first c in s outside of a basic or
+// literal string, or -1
+func indexUnquoted(s string, c byte) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quote == '"' && s[i] == '\\':
+			i++
+		case quote != 0:
+			if s[i] == quote {
+				quote = 0
+			}
+		case s[i] == '"' || s[i] == '\'':
+			quote = s[i]
+		case s[i] == c:
+			return i
+		}
+	}
+	return -1
+}
+
+// stripComment removes a trailing # comment that is not inside a string
+func stripComment(line string) string {
+	if i := indexUnquoted(line, '#'); i >= 0 {
+		return line[:i]
+	}
+	return line
+}
+
+// parseKey parses a possibly dotted key of bare or quoted segments into
+// its segments. Dots inside quotes belong to the segment.
+func parseKey(s string) ([]string, error) {
+	if s == "" {
+		return nil, fmt.Errorf("empty key")
+	}
+
+	var parts []string
+	for rest := s; ; {
+		part := rest
+		dot := indexUnquoted(rest, '.')
+		if dot >= 0 {
+			part, rest = rest[:dot], rest[dot+1:]
+		}
+		part = strings.TrimSpace(part)
+
+		if part != "" && (part[0] == '"' || part[0] == '\'') {
+			unquoted, err := parseString(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid key %q", s)
+			}
+			parts = append(parts, unquoted)
+		} else if isBareKey(part) {
+			parts = append(parts, part)
+		} else {
+			return nil, fmt.Errorf("invalid key %q", s)
+		}
+
+		if dot < 0 {
+			return parts, nil
+		}
+	}
+}
+
+// isBareKey reports whether a key segment can be written without quotes
+func isBareKey(s string) bool {
+	return s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-')
+	}) < 0
+}
+
+// formatKey joins key segments with dots, quoting those that are not bare
+// so that the result reads back as the same segments
+func formatKey(path []string) string {
+	parts := make([]string, len(path))
+	for i, part := range path {
+		if isBareKey(part) {
+			parts[i] = part
+		} else {
+			parts[i] = strconv.Quote(part)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// arrayClosed reports whether an array literal has its closing bracket
+func arrayClosed(s string) bool {
+	return indexUnquoted(s, ']') >= 0
+}
+
+func parseValue(s string) (any, error) {
+	switch {
+	case s == "":
+		return nil, fmt.Errorf("missing value")
+	case s == "true":
+		return true, nil
+	case s == "false":
+		return false, nil
+	case s[0] == '"' || s[0] == '\'':
+		return parseString(s)
+	case s[0] == '[':
+		return parseArray(s)
+	}
+
+	clean := strings.ReplaceAll(s, "_", "")
+	if n, err := strconv.ParseInt(clean, 10, 64); err == nil {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(clean, 64); err == nil {
+		return f, nil
+	}
+	return nil, fmt.Errorf("invalid value %q", s)
+}
+
+func parseString(s string) (string, error) {
+	value, n, err := scanString(s)
+	if err != nil {
+		return "", err
+	}
+	if n != len(s) {
+		return "", fmt.Errorf("invalid string %s", s)
+	}
+	return value, nil
+}
+
+// tomlEscapes are the single-character escapes of basic strings
+var tomlEscapes = map[byte]rune{
+	'b': '\b', 't': '\t', 'n': '\n', 'f': '\f', 'r': '\r', 'e': '\x1b', '"': '"', '\\': '\\',
+}
+
+// scanString decodes the basic ("...") or literal ('...') string at the
+// start of s, returning it and the length it takes up in s. Only basic
+// strings have escapes.
+func scanString(s string) (string, int, error) {
+	quote := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == quote:
+			return b.String(), i + 1, nil
+		case c < 0x20 && c != '\t' || c == 0x7f:
+			return "", 0, fmt.Errorf("control character in string %s", s)
+		case c != '\\' || quote == '\'':
+			b.WriteByte(c)
+		default:
+			r, n, ok := unescape(s[i:])
+			if !ok {
+				return "", 0, fmt.Errorf("invalid escape in string %s", s)
+			}
+			b.WriteRune(r)
+			i += n - 1
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string %s", s)
+}
+
+// unescape decodes the escape sequence at the start of s: one of
+// tomlEscapes, \uXXXX or \UXXXXXXXX. It returns the character and the
+// length of the sequence.
+func unescape(s string) (rune, int, bool) {
+	if len(s) < 2 {
+		return 0, 0, false
+	}
+	if r, ok := tomlEscapes[s[1]]; ok {
+		return r, 2, true
+	}
+
+	var digits int
+	switch s[1] {
+	case 'u':
+		digits = 4
+	case 'U':
+		digits = 8
+	default:
+		return 0, 0, false
+	}
+	if len(s) < 2+digits {
+		return 0, 0, false
+	}
+	code, err := strconv.ParseUint(s[2:2+digits], 16, 32)
+	if err != nil || !utf8.ValidRune(rune(code)) {
+		return 0, 0, false
+	}
+	return rune(code), 2 + digits, true
+}
+
+func parseArray(s string) ([]string, error) {
+	if !strings.HasSuffix(s, "]") {
+		return nil, fmt.Errorf("unterminated array %s", s)
+	}
+	body := strings.TrimSpace(s[1 : len(s)-1])
+
+	values := []string{}
+	for body != "" {
+		if body[0] != '"' && body[0] != '\'' {
+			return nil, fmt.Errorf("arrays may only contain strings: %s", s)
+		}
+
+		value, n, err := scanString(body)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		body = strings.TrimSpace(body[n:])
+		if strings.HasPrefix(body, ",") {
+			body = strings.TrimSpace(body[1:])
+		} else if body != "" {
+			return nil, fmt.Errorf("expected , between array elements: %s", s)
+		}
+	}
+
+	return values, nil
+}
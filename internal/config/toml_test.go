@@ -0,0 +1,69 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseTOMLStrings(t *testing.T) {
+	doc, err := parseTOML(`
+theme = 'dark'                       # literal strings have no escapes
+path = 'C:\Users\#1'
+title = "tab\there \u00e9\U0001F600 \e[1m"
+'quoted key' = "a \"quote\" and \\"
+fields = ['CPU', "Mem#ory",
+  'Disk' ]
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"theme":        "dark",
+		"path":         `C:\Users\#1`,
+		"title":        "tab\there é😀 \x1b[1m",
+		`"quoted key"`: `a "quote" and \`,
+		"fields":       []string{"CPU", "Mem#ory", "Disk"},
+	}
+	for _, e := range doc.Entries {
+		switch w := want[e.Key].(type) {
+		case string:
+			if e.Value != w {
+				t.Errorf("%s = %q, want %q", e.Key, e.Value, w)
+			}
+		case []string:
+			if got, _ := e.Value.([]string); !slices.Equal(got, w) {
+				t.Errorf("%s = %q, want %q", e.Key, e.Value, w)
+			}
+		default:
+			t.Errorf("unexpected key %q", e.Key)
+		}
+	}
+
+	// Go's escapes are not TOML's
+	for _, value := range []string{`"\'"`, `"\x41"`, `"\a"`, `"\uD800"`, `"\U00110000"`, `"\u12"`} {
+		if _, err := parseTOML("title = " + value); err == nil {
+			t.Errorf("parseTOML accepted %s", value)
+		}
+	}
+}
+
+func TestParseTOMLQuotedKeys(t *testing.T) {
+	doc, err := parseTOML(`
+"a.b" = 1
+site."x = y".name = 2
+[sections.'Odd.Name']
+c = 3
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"a.b"}, {"site", "x = y", "name"}, {"sections", "Odd.Name", "c"}}
+	for i, e := range doc.Entries {
+		if i >= len(want) || !slices.Equal(e.Path, want[i]) {
+			t.Errorf("entry %d = %q (%s)", i, e.Path, e.Key)
+		}
+	}
+	if len(doc.Entries) != len(want) {
+		t.Errorf("%d entries, want %d", len(doc.Entries), len(want))
+	}
+}
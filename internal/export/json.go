@@ -0,0 +1,153 @@
+// Package export serializes collected sections for consumption by other
+// tools. The JSON layout is described in docs/json-schema.md.
+package export
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+
+	"peekfetch/internal/types"
+)
+
+// SchemaVersion is bumped whenever the JSON layout changes in a way that
+// could break existing consumers
+const SchemaVersion = 1
+
+// Document is the top-level JSON object
+type Document struct {
+	SchemaVersion int       `json:"schema_version"`
+	Sections      []Section `json:"sections"`
+}
+
+// Section is one collected section. Flat sections carry Fields, tree
+// sections such as Disk and Network carry Items and table sections such as
+// Processes carry a Table.
+type Section struct {
+	Name   string  `json:"name"`
+	Fields []Field `json:"fields,omitempty"`
+	Items  []Item  `json:"items,omitempty"`
+	Table  *Table  `json:"table,omitempty"`
+}
+
+// Table is the rows of a table section, such as one per process
+type Table struct {
+	Columns []string `json:"columns"`
+	Rows    []Row    `json:"rows"`
+}
+
+// Row is one table row. Fields holds one field per column, named after it.
+type Row struct {
+	ID     string  `json:"id"`
+	Fields []Field `json:"fields"`
+}
+
+// Item is one entry of a tree section, such as a partition or interface
+type Item struct {
+	Name   string  `json:"name"`
+	Fields []Field `json:"fields"`
+}
+
+// Field is a single key/value pair. Value is a number when Unit is set and
+// the display string otherwise.
+type Field struct {
+	Name    string   `json:"name"`
+	Value   any      `json:"value"`
+	Unit    string   `json:"unit,omitempty"`
+	Max     *float64 `json:"max,omitempty"`
+	Display string   `json:"display"`
+}
+
+// JSON encodes sections as an indented schema document
+func JSON(sections []types.Section) ([]byte, error) {
+	return json.MarshalIndent(NewDocument(sections), "", "  ")
+}
+
+// NewDocument converts sections into the export schema
+func NewDocument(sections []types.Section) Document {
+	doc := Document{
+		SchemaVersion: SchemaVersion,
+		Sections:      make([]Section, 0, len(sections)),
+	}
+
+	for _, section := range sections {
+		out := Section{Name: section.Name}
+		if section.UseTree {
+			out.Items = make([]Item, 0, len(section.TreeData))
+			for _, item := range section.TreeData {
+				out.Items = append(out.Items, Item{
+					Name:   item.Name,
+					Fields: fields(item.Order, item.Children),
+				})
+			}
+		} else {
+			out.Fields = fields(section.Order, section.Data)
+		}
+		if section.Table != nil {
+			out.Table = table(section.Table)
+		}
+		doc.Sections = append(doc.Sections, out)
+	}
+
+	return doc
+}
+
+func fields(order []string, data map[string]types.Field) []Field {
+	if len(order) == 0 {
+		for key := range data {
+			order = append(order, key)
+		}
+		sort.Strings(order)
+	}
+
+	out := make([]Field, 0, len(order))
+	for _, key := range order {
+		value, ok := data[key]
+		if !ok {
+			continue
+		}
+
+		display := value.String()
+		field := Field{Name: key, Value: display, Display: display}
+		if value.IsNumeric() {
+			field.Value = number(value)
+			field.Unit = value.Unit
+			if value.Kind != types.KindPercent && value.Max > 0 {
+				max := value.Max
+				field.Max = &max
+			}
+		}
+		out = append(out, field)
+	}
+	return out
+}
+
+func table(t *types.Table) *Table {
+	out := &Table{
+		Columns: make([]string, 0, len(t.Columns)),
+		Rows:    make([]Row, 0, len(t.Rows)),
+	}
+	for _, col := range t.Columns {
+		out.Columns = append(out.Columns, col.Name)
+	}
+
+	for _, row := range t.Rows {
+		data := make(map[string]types.Field, len(row.Cells))
+		for i, cell := range row.Cells {
+			if i < len(out.Columns) {
+				data[out.Columns[i]] = cell
+			}
+		}
+		out.Rows = append(out.Rows, Row{ID: row.ID, Fields: fields(out.Columns, data)})
+	}
+	return out
+}
+
+// number returns integral values as integers so that byte counts are not
+// rendered in exponent notation
+func number(value types.Field) any {
+	if value.IsIntegral() {
+		return int64(math.Round(value.Value))
+	}
+	return value.Value
+}
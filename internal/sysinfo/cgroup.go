@@ -0,0 +1,426 @@
+package sysinfo
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"peekfetch/internal/types"
+)
+
+// cgroupRoot is where the cgroup hierarchies are mounted
+const cgroupRoot = "/sys/fs/cgroup"
+
+// cgroupUnlimited is the smallest value cgroup v1 uses for no limit; the
+// kernel rounds the largest int64 down to a page
+const cgroupUnlimited = 1 << 62
+
+// cgroupV1Controllers are the v1 hierarchies limits are read from
+var cgroupV1Controllers = []string{"memory", "cpu", "cpuacct", "cpuset", "pids"}
+
+// cgroup is the control group a process belongs to
+type cgroup struct {
+	version int
+	path    string              // As listed in /proc/<pid>/cgroup
+	dirs    map[string][]string // By v1 controller, or "" for v2: the cgroup's directory, then its ancestors up to the mount
+}
+
+// chain returns the directories a controller's files are read from, the
+// process's own cgroup first
+func (cg cgroup) chain(controller string) []string {
+	if cg.version == 2 {
+		return cg.dirs[""]
+	}
+	return cg.dirs[controller]
+}
+
+// readCgroup finds the cgroup of peekfetch, or of PID 1 below an alternate
+// root. A v2 hierarchy is preferred over v1 controllers on hybrid systems.
+func readCgroup(h *Host) (cgroup, bool) {
+	proc := "/proc/self/cgroup"
+	if !h.IsLocal() {
+		proc = "/proc/1/cgroup"
+	}
+	data, err := h.ReadFile(proc)
+	if err != nil {
+		return cgroup{}, false
+	}
+
+	v1 := cgroup{version: 1, dirs: make(map[string][]string)}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		// Format is "hierarchy-ID:controllers:path"; the v2 hierarchy has
+		// ID 0 and no controllers
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if parts[0] == "0" && parts[1] == "" {
+			if h.Exists(cgroupRoot + "/cgroup.controllers") {
+				dirs := map[string][]string{"": cgroupDirs(h, cgroupRoot, parts[2])}
+				return cgroup{version: 2, path: parts[2], dirs: dirs}, true
+			}
+			continue
+		}
+		for _, controller := range strings.Split(parts[1], ",") {
+			if !slices.Contains(cgroupV1Controllers, controller) {
+				continue
+			}
+			// Co-mounted controllers share a directory, such as "cpu,cpuacct"
+			v1.dirs[controller] = cgroupDirs(h, cgroupRoot+"/"+parts[1], parts[2])
+			if v1.path == "" || controller == "memory" {
+				v1.path = parts[2]
+			}
+		}
+	}
+	if len(v1.dirs) == 0 {
+		return cgroup{}, false
+	}
+	return v1, true
+}
+
+// cgroupDirs lists the directory of a cgroup and those of its ancestors in
+// a hierarchy mounted at mount. In a cgroup namespace, or when a container
+// runtime mounts only its own cgroup, the path is not found below the
+// mount, whose root is then the process's cgroup.
+func cgroupDirs(h *Host, mount, cgroupPath string) []string {
+	dir := path.Join(mount, cgroupPath)
+	if !strings.HasPrefix(dir, mount) || !h.Exists(dir) {
+		dir = mount
+	}
+	dirs := []string{dir}
+	for dir != mount {
+		dir = path.Dir(dir)
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// cgroupValue reads a limit from a cgroup file, 0 meaning unlimited
+func cgroupValue(h *Host, file string) (uint64, bool) {
+	s, err := h.ReadString(file)
+	if err != nil {
+		return 0, false
+	}
+	if s == "max" {
+		return 0, true
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	if n >= cgroupUnlimited {
+		return 0, true
+	}
+	return n, true
+}
+
+// effectiveLimit is the lowest limit set in a file by a cgroup or any of
+// its ancestors, 0 if none sets one
+func effectiveLimit(h *Host, dirs []string, file string) uint64 {
+	var limit uint64
+	for _, dir := range dirs {
+		if n, ok := cgroupValue(h, dir+"/"+file); ok && n > 0 && (limit == 0 || n < limit) {
+			limit = n
+		}
+	}
+	return limit
+}
+
+// firstValue reads a counter from the first directory that has the file
+func firstValue(h *Host, dirs []string, file string) (uint64, bool) {
+	for _, dir := range dirs {
+		if n, ok := cgroupValue(h, dir+"/"+file); ok {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+// cpuQuota reads the CPU bandwidth limit of one cgroup, in microseconds of
+// CPU time per period. v2 writes both in cpu.max; v1 uses -1 for no quota.
+func cpuQuota(h *Host, version int, dir string) (quota, period int64, ok bool) {
+	if version == 2 {
+		s, err := h.ReadString(dir + "/cpu.max")
+		if err != nil {
+			return 0, 0, false
+		}
+		q, p, _ := strings.Cut(s, " ")
+		period, err = strconv.ParseInt(p, 10, 64)
+		if err != nil || q == "max" {
+			return 0, period, err == nil
+		}
+		quota, err = strconv.ParseInt(q, 10, 64)
+		return quota, period, err == nil
+	}
+
+	quota, err := h.ReadInt(dir + "/cpu.cfs_quota_us")
+	if err != nil {
+		return 0, 0, false
+	}
+	period, err = h.ReadInt(dir + "/cpu.cfs_period_us")
+	if err != nil {
+		return 0, 0, false
+	}
+	return max(quota, 0), period, true
+}
+
+// countCPUs counts the CPUs in a list such as "0-3,6"
+func countCPUs(list string) int {
+	count := 0
+	for _, part := range strings.Split(list, ",") {
+		first, last, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		lo, err := strconv.Atoi(first)
+		if err != nil {
+			continue
+		}
+		hi := lo
+		if isRange {
+			if hi, err = strconv.Atoi(last); err != nil || hi < lo {
+				continue
+			}
+		}
+		count += hi - lo + 1
+	}
+	return count
+}
+
+// cgroupLimits are the effective limits of a cgroup and its use of them
+type cgroupLimits struct {
+	memory     uint64 // Bytes, 0 if unlimited
+	memoryHigh uint64 // Throttling threshold (v2 only), 0 if unlimited
+	memoryUsed uint64
+	hasMemory  bool   // Whether memoryUsed could be read
+	quota      int64  // Microseconds of CPU time per period, 0 if unlimited
+	period     int64  // Microseconds
+	cpuset     string // CPUs the cgroup may run on, such as "0-3"
+	pids       uint64
+	hasPids    bool   // Whether pids could be read
+	pidsMax    uint64 // 0 if unlimited
+}
+
+// cpus is how many CPUs' worth of time the cgroup may use: the lower of
+// its quota and the CPUs in its set, 0 if neither is known
+func (l cgroupLimits) cpus() float64 {
+	n := float64(countCPUs(l.cpuset))
+	if l.quota > 0 && l.period > 0 {
+		if quota := float64(l.quota) / float64(l.period); n == 0 || quota < n {
+			return quota
+		}
+	}
+	return n
+}
+
+// limits reads the effective limits of a cgroup. An ancestor's limit
+// applies when it is lower than the cgroup's own.
+func (cg cgroup) limits(h *Host) cgroupLimits {
+	var l cgroupLimits
+
+	memory := cg.chain("memory")
+	if cg.version == 2 {
+		l.memory = effectiveLimit(h, memory, "memory.max")
+		l.memoryHigh = effectiveLimit(h, memory, "memory.high")
+		l.memoryUsed, l.hasMemory = firstValue(h, memory, "memory.current")
+	} else {
+		l.memory = effectiveLimit(h, memory, "memory.limit_in_bytes")
+		l.memoryUsed, l.hasMemory = firstValue(h, memory, "memory.usage_in_bytes")
+	}
+
+	for _, dir := range cg.chain("cpu") {
+		quota, period, ok := cpuQuota(h, cg.version, dir)
+		if !ok || quota == 0 || period == 0 {
+			continue
+		}
+		if l.quota == 0 || float64(quota)/float64(period) < float64(l.quota)/float64(l.period) {
+			l.quota, l.period = quota, period
+		}
+	}
+
+	// The effective set already accounts for the ancestors
+	files := []string{"cpuset.effective_cpus", "cpuset.cpus"}
+	if cg.version == 2 {
+		files = []string{"cpuset.cpus.effective"}
+	}
+	for _, dir := range cg.chain("cpuset") {
+		for _, file := range files {
+			if cpus, err := h.ReadString(dir + "/" + file); err == nil && cpus != "" {
+				l.cpuset = cpus
+				break
+			}
+		}
+		if l.cpuset != "" {
+			break
+		}
+	}
+
+	pids := cg.chain("pids")
+	l.pidsMax = effectiveLimit(h, pids, "pids.max")
+	l.pids, l.hasPids = firstValue(h, pids, "pids.current")
+	return l
+}
+
+// cpuUsage reads the CPU time the cgroup has used since it was created
+func (cg cgroup) cpuUsage(h *Host) (time.Duration, bool) {
+	if cg.version == 1 {
+		ns, ok := firstValue(h, cg.chain("cpuacct"), "cpuacct.usage")
+		return time.Duration(ns), ok
+	}
+	for _, dir := range cg.chain("cpu") {
+		data, err := h.ReadFile(dir + "/cpu.stat")
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if usec, ok := strings.CutPrefix(line, "usage_usec "); ok {
+				n, err := strconv.ParseUint(usec, 10, 64)
+				return time.Duration(n) * time.Microsecond, err == nil
+			}
+		}
+	}
+	return 0, false
+}
+
+// getCgroupLimits returns the limits of the current cgroup, for the Memory
+// and CPU sections
+func getCgroupLimits(h *Host) (cgroupLimits, bool) {
+	cg, ok := readCgroup(h)
+	if !ok {
+		return cgroupLimits{}, false
+	}
+	return cg.limits(h), true
+}
+
+// GetLimitsInfo collects the effective resource limits of the cgroup
+// peekfetch runs in. Unlike the Memory and CPU sections, which describe the
+// whole host, these apply only to the cgroup, such as a container.
+func GetLimitsInfo(ctx context.Context) types.Section {
+	h := hostOf(ctx)
+	info := make(map[string]types.Field)
+	order := []string{}
+	add := func(key string, value types.Field) {
+		info[key] = value
+		order = append(order, key)
+	}
+	limit := func(bytes uint64) types.Field {
+		if bytes == 0 {
+			return types.Text("Unlimited")
+		}
+		return types.Bytes(bytes)
+	}
+
+	cg, ok := readCgroup(h)
+	if ok {
+		l := cg.limits(h)
+
+		scope := "Host"
+		if container := detectContainer(h); container != "" {
+			scope = container + " container"
+		}
+		add("Scope", types.Text(scope))
+		add("Cgroup", types.Text(cg.path))
+		add("Version", types.Text(fmt.Sprintf("v%d", cg.version)))
+
+		// A counter that cannot be read is left out rather than shown as 0
+		if l.hasMemory {
+			memory := types.Bytes(l.memoryUsed)
+			if l.memory > 0 {
+				memory = memory.WithMax(float64(l.memory))
+			}
+			add("Memory", memory)
+		}
+		add("Memory Limit", limit(l.memory))
+		if cg.version == 2 {
+			add("Memory High", limit(l.memoryHigh))
+		}
+
+		if l.quota > 0 {
+			add("CPU Quota", types.CPUs(float64(l.quota)/float64(l.period)))
+			add("Quota Runtime", types.Latency(time.Duration(l.quota)*time.Microsecond))
+			add("Quota Period", types.Latency(time.Duration(l.period)*time.Microsecond))
+		} else {
+			add("CPU Quota", types.Text("Unlimited"))
+		}
+		if l.cpuset != "" {
+			add("CPU Set", types.Text(fmt.Sprintf("%s (%d CPUs)", l.cpuset, countCPUs(l.cpuset))))
+		}
+		// Of the CPU time the cgroup may use (updated in live mode)
+		if _, ok := cg.cpuUsage(h); ok && l.cpus() > 0 {
+			add("CPU Usage", types.Percent(0))
+		}
+
+		if l.hasPids {
+			processes := types.Count(l.pids)
+			if l.pidsMax > 0 {
+				processes = processes.WithMax(float64(l.pidsMax))
+			}
+			add("Processes", processes)
+		}
+		if l.pidsMax > 0 {
+			add("Process Limit", types.Count(l.pidsMax))
+		} else {
+			add("Process Limit", types.Text("Unlimited"))
+		}
+	}
+
+	return types.Section{
+		Name:     "Limits",
+		Expanded: false,
+		Data:     info,
+		LiveData: true,
+		Order:    order,
+	}
+}
+
+// limitsCollector computes the cgroup's CPU usage from the change in its
+// accounted CPU time between refreshes
+type limitsCollector struct {
+	usage sampler[time.Duration] // CPU time used
+}
+
+func (c *limitsCollector) Name() string            { return "Limits" }
+func (c *limitsCollector) Interval() time.Duration { return DefaultInterval }
+
+func (c *limitsCollector) Collect(ctx context.Context) types.Section {
+	section := GetLimitsInfo(ctx)
+	c.sample(hostOf(ctx))
+	return section
+}
+
+func (c *limitsCollector) Refresh(ctx context.Context, section types.Section) types.Section {
+	h := hostOf(ctx)
+	updated := GetLimitsInfo(ctx)
+	data := updated.Data
+	usage, prev, elapsed, ok := c.sample(h)
+	if _, shown := data["CPU Usage"]; shown {
+		if old, ok := section.Data["CPU Usage"]; ok {
+			data["CPU Usage"] = old
+		}
+		l, limited := getCgroupLimits(h)
+		if ok && limited && usage >= prev {
+			percent := float64(usage-prev) / float64(elapsed) / l.cpus() * 100
+			data["CPU Usage"] = types.Percent(min(percent, 100))
+		}
+	}
+
+	section.Data = data
+	section.Order = updated.Order
+	return section
+}
+
+// sample reads the cgroup's CPU time as the baseline of the next refresh
+// and returns it with the previous reading
+func (c *limitsCollector) sample(h *Host) (usage, prev, elapsed time.Duration, ok bool) {
+	cg, ok := readCgroup(h)
+	if !ok {
+		return 0, 0, 0, false
+	}
+	usage, ok = cg.cpuUsage(h)
+	if !ok {
+		return 0, 0, 0, false
+	}
+	prev, elapsed, ok = c.usage.next(h, usage)
+	return usage, prev, elapsed, ok
+}
@@ -0,0 +1,88 @@
+package sysinfo
+
+import (
+	"testing"
+)
+
+func TestCgroupV1EffectiveLimits(t *testing.T) {
+	host, ctx := tempHost(t, map[string]string{
+		// The pids hierarchy is mounted but the root cgroup has no counter
+		"proc/1/cgroup":               "9:memory:/docker/3f2a\n4:cpu,cpuacct:/docker/3f2a\n3:cpuset:/docker/3f2a\n2:pids:/\n1:name=systemd:/docker/3f2a\n",
+		"sys/fs/cgroup/pids/pids.max": "max\n",
+		// The parent's limit is lower than the container's own
+		"sys/fs/cgroup/memory/memory.limit_in_bytes":              "9223372036854771712\n",
+		"sys/fs/cgroup/memory/docker/memory.limit_in_bytes":       "1073741824\n",
+		"sys/fs/cgroup/memory/docker/3f2a/memory.limit_in_bytes":  "4294967296\n",
+		"sys/fs/cgroup/memory/docker/3f2a/memory.usage_in_bytes":  "268435456\n",
+		"sys/fs/cgroup/cpu,cpuacct/docker/3f2a/cpu.cfs_quota_us":  "50000\n",
+		"sys/fs/cgroup/cpu,cpuacct/docker/3f2a/cpu.cfs_period_us": "100000\n",
+		"sys/fs/cgroup/cpuset/docker/3f2a/cpuset.cpus":            "0-1,4\n",
+	})
+
+	cg, ok := readCgroup(host)
+	if !ok || cg.version != 1 || cg.path != "/docker/3f2a" {
+		t.Fatalf("readCgroup = %+v, %v, want v1 /docker/3f2a", cg, ok)
+	}
+	l := cg.limits(host)
+	if l.memory != 1<<30 || l.memoryUsed != 256<<20 {
+		t.Errorf("memory = %d used of %d, want the parent's 1 GiB limit", l.memoryUsed, l.memory)
+	}
+	if l.cpus() != 0.5 {
+		t.Errorf("cpus = %v, want the 0.5 quota", l.cpus())
+	}
+	if l.cpuset != "0-1,4" || countCPUs(l.cpuset) != 3 {
+		t.Errorf("cpuset = %q, want 3 CPUs", l.cpuset)
+	}
+	if l.pidsMax != 0 {
+		t.Errorf("pidsMax = %d in the root pids cgroup, want unlimited", l.pidsMax)
+	}
+	if l.hasPids {
+		t.Errorf("pids = %d read without pids.current", l.pids)
+	}
+
+	section := GetLimitsInfo(ctx)
+	if value, ok := section.Data["Processes"]; ok {
+		t.Errorf("Processes = %q without pids.current, want it left out", value)
+	}
+	if got := section.Data["Memory"]; got.Value != 256<<20 || got.Max != 1<<30 {
+		t.Errorf("Memory = %+v, want 256 MiB used of 1 GiB", got)
+	}
+}
+
+func TestCgroupV2Namespace(t *testing.T) {
+	// Moved out of the namespace's root, the cgroup's path climbs above
+	// the mount, which is the container's own cgroup
+	host, ctx := tempHost(t, map[string]string{
+		"proc/1/cgroup":                    "0::/../../system.slice/docker-3f2a.scope\n",
+		"sys/fs/cgroup/cgroup.controllers": "cpu memory pids\n",
+		"sys/fs/cgroup/memory.max":         "max\n",
+		"sys/fs/cgroup/memory.current":     "1048576\n",
+		"sys/fs/cgroup/cpu.max":            "200000 100000\n",
+		"sys/fs/cgroup/cpu.stat":           "usage_usec 1000000\nuser_usec 600000\n",
+		"sys/fs/cgroup/pids.max":           "64\n",
+		"sys/fs/cgroup/pids.current":       "16\n",
+		"proc/uptime":                      "100.00 350.00\n",
+	})
+
+	c := &limitsCollector{}
+	section := c.Collect(ctx)
+	if got := section.Data["Version"].Text; got != "v2" {
+		t.Fatalf("Version = %q, want v2", got)
+	}
+	if got := section.Data["Memory Limit"].String(); got != "Unlimited" {
+		t.Errorf("Memory Limit = %q, want Unlimited", got)
+	}
+	if got := section.Data["Processes"].String(); got != "16 / 64" {
+		t.Errorf("Processes = %q, want 16 / 64", got)
+	}
+
+	// Half a second of CPU time in one second, of the two CPUs allowed
+	writeFiles(t, host, map[string]string{
+		"sys/fs/cgroup/cpu.stat": "usage_usec 1500000\nuser_usec 900000\n",
+		"proc/uptime":            "101.00 352.00\n",
+	})
+	section = c.Refresh(ctx, section)
+	if got := section.Data["CPU Usage"].String(); got != "25.0%" {
+		t.Errorf("CPU Usage = %q, want 25.0%%", got)
+	}
+}
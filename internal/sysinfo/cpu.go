@@ -1,10 +1,13 @@
 package sysinfo
 
 import (
+	"context"
 	"fmt"
-	"os"
+	"maps"
 	"runtime"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"peekfetch/internal/types"
@@ -13,50 +16,49 @@ import (
 )
 
 // GetCPUInfo collects detailed CPU information
-func GetCPUInfo() types.Section {
-	info := make(map[string]string)
+func GetCPUInfo(ctx context.Context) types.Section {
+	info := make(map[string]types.Field)
 	order := []string{}
 
-	cpuInfo, _ := cpu.Info()
+	cpuInfo, _ := cpu.InfoWithContext(ctx)
 	if len(cpuInfo) > 0 {
 		// Model
-		info["Model"] = cpuInfo[0].ModelName
+		info["Model"] = types.Text(cpuInfo[0].ModelName)
 		order = append(order, "Model")
 
 		// Vendor
 		if cpuInfo[0].VendorID != "" {
-			info["Vendor"] = cpuInfo[0].VendorID
+			info["Vendor"] = types.Text(cpuInfo[0].VendorID)
 			order = append(order, "Vendor")
 		}
 
 		// CPU family
 		if cpuInfo[0].Family != "" {
-			info["Family"] = cpuInfo[0].Family
+			info["Family"] = types.Text(cpuInfo[0].Family)
 			order = append(order, "Family")
 		}
 
 		// CPU model number
 		if cpuInfo[0].Model != "" {
-			info["Model ID"] = cpuInfo[0].Model
+			info["Model ID"] = types.Text(cpuInfo[0].Model)
 			order = append(order, "Model ID")
 		}
 
 		// Stepping
 		if cpuInfo[0].Stepping != 0 {
-			info["Stepping"] = fmt.Sprintf("%d", cpuInfo[0].Stepping)
+			info["Stepping"] = types.Count(uint64(cpuInfo[0].Stepping))
 			order = append(order, "Stepping")
 		}
 
 		// Frequency
 		if cpuInfo[0].Mhz > 0 {
-			ghz := cpuInfo[0].Mhz / 1000
-			info["Frequency"] = fmt.Sprintf("%.2f GHz", ghz)
+			info["Frequency"] = types.Frequency(cpuInfo[0].Mhz * 1e6)
 			order = append(order, "Frequency")
 		}
 
 		// Cache size
 		if cpuInfo[0].CacheSize > 0 {
-			info["Cache Size"] = fmt.Sprintf("%d KB", cpuInfo[0].CacheSize)
+			info["Cache Size"] = types.Bytes(uint64(cpuInfo[0].CacheSize) * 1024)
 			order = append(order, "Cache Size")
 		}
 
@@ -66,39 +68,65 @@ func GetCPUInfo() types.Section {
 			if len(flags) > 10 {
 				flags = flags[:10]
 			}
-			info["Features"] = strings.Join(flags, ", ") + "..."
+			info["Features"] = types.Text(strings.Join(flags, ", ") + "...")
 			order = append(order, "Features")
 		}
 	}
 
 	// Physical and logical cores
-	physicalCores, _ := cpu.Counts(false)
-	logicalCores := runtime.NumCPU()
+	physicalCores, _ := cpu.CountsWithContext(ctx, false)
+	logicalCores, _ := cpu.CountsWithContext(ctx, true)
+	if logicalCores == 0 {
+		logicalCores = runtime.NumCPU()
+	}
 
 	if physicalCores > 0 {
-		info["Physical Cores"] = fmt.Sprintf("%d", physicalCores)
+		info["Physical Cores"] = types.Count(uint64(physicalCores))
 		order = append(order, "Physical Cores")
 	}
 
-	info["Logical Cores"] = fmt.Sprintf("%d", logicalCores)
+	info["Logical Cores"] = types.Count(uint64(logicalCores))
 	order = append(order, "Logical Cores")
 
 	// Threads per core
 	if physicalCores > 0 && logicalCores > 0 {
 		threadsPerCore := logicalCores / physicalCores
-		info["Threads/Core"] = fmt.Sprintf("%d", threadsPerCore)
+		info["Threads/Core"] = types.Count(uint64(threadsPerCore))
 		order = append(order, "Threads/Core")
 	}
 
-	// Temperature (if available)
-	if temp := getCPUTemperature(); temp != "" {
-		info["Temperature"] = temp
+	// The cores are the host's; a cgroup may be allowed fewer of them
+	if l, ok := getCgroupLimits(hostOf(ctx)); ok {
+		if cpus := l.cpus(); cpus > 0 && cpus < float64(logicalCores) {
+			info["Cgroup CPUs"] = types.CPUs(cpus).WithMax(float64(logicalCores))
+			order = append(order, "Cgroup CPUs")
+		}
+	}
+
+	// Temperature (if a CPU sensor driver is loaded)
+	if temperature, ok := getCPUTemperature(hostOf(ctx)); ok {
+		info["Temperature"] = temperature
 		order = append(order, "Temperature")
 	}
 
-	// Usage placeholder (will be updated in live mode)
-	info["Usage"] = "0.0%"
-	order = append(order, "Usage")
+	// Usage placeholders (will be updated in live mode)
+	for _, key := range []string{"Usage", "User", "System", "I/O Wait", "IRQ", "Steal"} {
+		info[key] = types.Percent(0)
+		order = append(order, key)
+	}
+
+	// Per-CPU usage, drawn as a grid of bars
+	var grids [][]string
+	if stat, err := readCPUStat(hostOf(ctx)); err == nil && len(stat.cpus) > 0 {
+		perCPU := make([]string, 0, len(stat.cpus))
+		for n := range stat.cpus {
+			key := cpuLabel(n)
+			info[key] = types.Percent(0)
+			perCPU = append(perCPU, key)
+		}
+		order = append(order, perCPU...)
+		grids = append(grids, perCPU)
+	}
 
 	return types.Section{
 		Name:     "CPU",
@@ -106,47 +134,182 @@ func GetCPUInfo() types.Section {
 		Data:     info,
 		LiveData: true,
 		Order:    order,
+		Grids:    grids,
 	}
 }
 
-// GetCPUUsage gets current CPU usage (live data)
-func GetCPUUsage() string {
-	percent, err := cpu.Percent(100*time.Millisecond, false)
-	if err != nil || len(percent) == 0 {
-		return "N/A"
-	}
-	return fmt.Sprintf("%.1f%%", percent[0])
+// cpuTimes is one line of CPU time counters from /proc/stat, in jiffies
+type cpuTimes struct {
+	user, nice, system, idle, iowait, irq, softirq, steal uint64
+}
+
+// total returns every accounted jiffy. Guest time is already included in
+// user and nice, so it is not summed.
+func (t cpuTimes) total() uint64 {
+	return t.user + t.nice + t.system + t.idle + t.iowait + t.irq + t.softirq + t.steal
 }
 
-func getCPUTemperature() string {
-	// Try different thermal zone files
-	thermalPaths := []string{
-		"/sys/class/thermal/thermal_zone0/temp",
-		"/sys/class/thermal/thermal_zone1/temp",
-		"/sys/class/hwmon/hwmon0/temp1_input",
-		"/sys/class/hwmon/hwmon1/temp1_input",
+// cpuStat holds the aggregate counters and those of each logical CPU
+type cpuStat struct {
+	all  cpuTimes
+	cpus []cpuTimes // By CPU number
+}
+
+// readCPUStat reads the "cpu" and "cpuN" lines of /proc/stat
+func readCPUStat(h *Host) (cpuStat, error) {
+	data, err := h.ReadFile("/proc/stat")
+	if err != nil {
+		return cpuStat{}, err
 	}
 
-	for _, path := range thermalPaths {
-		data, err := os.ReadFile(path)
-		if err != nil {
+	var stat cpuStat
+	found := false
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 5 || !strings.HasPrefix(fields[0], "cpu") {
 			continue
 		}
 
-		var temp int
-		_, err = fmt.Sscanf(string(data), "%d", &temp)
-		if err != nil {
+		// Format is "cpu user nice system idle iowait irq softirq steal guest guest_nice"
+		var values [8]uint64
+		for i, field := range fields[1:] {
+			if i >= len(values) {
+				break
+			}
+			if values[i], err = strconv.ParseUint(field, 10, 64); err != nil {
+				return cpuStat{}, err
+			}
+		}
+		times := cpuTimes{
+			user: values[0], nice: values[1], system: values[2], idle: values[3],
+			iowait: values[4], irq: values[5], softirq: values[6], steal: values[7],
+		}
+
+		if fields[0] == "cpu" {
+			stat.all = times
+			found = true
 			continue
 		}
+		n, err := strconv.Atoi(fields[0][3:])
+		if err != nil || n < 0 {
+			continue
+		}
+		// Offline CPUs have no line, so grow the slice to fit the number
+		for len(stat.cpus) <= n {
+			stat.cpus = append(stat.cpus, cpuTimes{})
+		}
+		stat.cpus[n] = times
+	}
+
+	if !found {
+		return cpuStat{}, fmt.Errorf("no cpu line in /proc/stat")
+	}
+	return stat, nil
+}
 
-		// Temperature is in millidegrees
-		celsius := float64(temp) / 1000.0
+// cpuUsage is how time was spent between two samples, in percent
+type cpuUsage struct {
+	busy   float64 // Everything but idle and I/O wait
+	user   float64 // Including nice
+	system float64
+	iowait float64
+	irq    float64 // Hard and soft interrupts
+	steal  float64
+}
 
-		// Only return if reasonable (between 0 and 150°C)
-		if celsius > 0 && celsius < 150 {
-			return fmt.Sprintf("%.1f°C", celsius)
+// usageSince returns the usage between an earlier sample and t, or false
+// if no CPU time has been accounted since
+func (t cpuTimes) usageSince(prev cpuTimes) (cpuUsage, bool) {
+	if t.total() <= prev.total() {
+		return cpuUsage{}, false
+	}
+
+	total := float64(t.total() - prev.total())
+	percent := func(now, before uint64) float64 {
+		if now < before {
+			return 0
+		}
+		return float64(now-before) / total * 100
+	}
+
+	idle := percent(t.idle+t.iowait, prev.idle+prev.iowait)
+	return cpuUsage{
+		busy:   100 - idle,
+		user:   percent(t.user+t.nice, prev.user+prev.nice),
+		system: percent(t.system, prev.system),
+		iowait: percent(t.iowait, prev.iowait),
+		irq:    percent(t.irq+t.softirq, prev.irq+prev.softirq),
+		steal:  percent(t.steal, prev.steal),
+	}, true
+}
+
+// cpuLabel is the field name of a logical CPU's usage
+func cpuLabel(n int) string {
+	return fmt.Sprintf("CPU %d", n)
+}
+
+// cpuCollector computes usage from the change in /proc/stat counters
+// between refreshes, so sampling never has to sleep
+type cpuCollector struct {
+	mu   sync.Mutex
+	prev cpuStat
+}
+
+func (c *cpuCollector) Name() string            { return "CPU" }
+func (c *cpuCollector) Interval() time.Duration { return DefaultInterval }
+
+func (c *cpuCollector) Collect(ctx context.Context) types.Section {
+	section := GetCPUInfo(ctx)
+
+	// Take the first sample so the first refresh has a baseline
+	if stat, err := readCPUStat(hostOf(ctx)); err == nil {
+		c.mu.Lock()
+		c.prev = stat
+		c.mu.Unlock()
+	}
+
+	return section
+}
+
+func (c *cpuCollector) Refresh(ctx context.Context, section types.Section) types.Section {
+	section.Data = maps.Clone(section.Data)
+	if section.Data == nil {
+		section.Data = make(map[string]types.Field)
+	}
+
+	stat, err := readCPUStat(hostOf(ctx))
+	if err != nil {
+		section.Data["Usage"] = types.Text("N/A")
+		return section
+	}
+
+	c.mu.Lock()
+	prev := c.prev
+	usage, ok := stat.all.usageSince(prev.all)
+	if ok {
+		c.prev = stat
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		return section
+	}
+
+	section.Data["Usage"] = types.Percent(usage.busy)
+	section.Data["User"] = types.Percent(usage.user)
+	section.Data["System"] = types.Percent(usage.system)
+	section.Data["I/O Wait"] = types.Percent(usage.iowait)
+	section.Data["IRQ"] = types.Percent(usage.irq)
+	section.Data["Steal"] = types.Percent(usage.steal)
+
+	for n, times := range stat.cpus {
+		if n >= len(prev.cpus) {
+			break
+		}
+		if usage, ok := times.usageSince(prev.cpus[n]); ok {
+			section.Data[cpuLabel(n)] = types.Percent(usage.busy)
 		}
 	}
 
-	return ""
+	return section
 }
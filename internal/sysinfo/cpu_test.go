@@ -0,0 +1,49 @@
+package sysinfo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCPURefreshFromDeltas(t *testing.T) {
+	host, ctx := tempHost(t, map[string]string{
+		"proc/stat": "cpu  100 0 100 800 0 0 0 0 0 0\n" +
+			"cpu0 50 0 50 400 0 0 0 0 0 0\n" +
+			"cpu1 50 0 50 400 0 0 0 0 0 0\n",
+	})
+	c := &cpuCollector{}
+	section := c.Collect(ctx)
+
+	// cpu0 is pegged in user time, cpu1 loses half of its time to steal
+	writeFiles(t, host, map[string]string{
+		"proc/stat": "cpu  300 0 100 800 0 0 0 100 0 0\n" +
+			"cpu0 150 0 50 400 0 0 0 0 0 0\n" +
+			"cpu1 150 0 50 400 0 0 0 100 0 0\n",
+	})
+	section = c.Refresh(ctx, section)
+
+	want := map[string]float64{
+		"Usage":    100,
+		"User":     200.0 / 3,
+		"System":   0,
+		"I/O Wait": 0,
+		"IRQ":      0,
+		"Steal":    100.0 / 3,
+		"CPU 0":    100,
+		"CPU 1":    100,
+	}
+	for key, value := range want {
+		got, ok := section.Data[key]
+		if !ok {
+			t.Errorf("%s missing", key)
+			continue
+		}
+		if math.Abs(got.Value-value) > 1e-9 {
+			t.Errorf("%s = %v, want %v", key, got.Value, value)
+		}
+	}
+
+	if len(section.Grids) != 1 || len(section.Grids[0]) != 2 {
+		t.Errorf("Grids = %v, want one grid of both CPUs", section.Grids)
+	}
+}
@@ -1,73 +1,78 @@
 package sysinfo
 
 import (
+	"context"
 	"fmt"
-	"os/exec"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"peekfetch/internal/types"
 
 	"github.com/shirou/gopsutil/v3/disk"
 )
 
-// GetDiskInfo collects detailed disk information for all mounted partitions
-func GetDiskInfo() types.Section {
+// DefaultHiddenFilesystems are the pseudo filesystem types left out of the
+// Disk section unless configured otherwise
+var DefaultHiddenFilesystems = []string{
+	"tmpfs", "devtmpfs", "devpts", "sysfs", "proc",
+	"cgroup", "cgroup2", "pstore", "bpf", "tracefs",
+	"debugfs", "hugetlbfs", "mqueue", "configfs",
+	"securityfs", "fusectl", "fuse.portal",
+}
+
+// GetDiskInfo collects detailed disk information for all mounted partitions,
+// skipping the given filesystem types
+func GetDiskInfo(ctx context.Context, hiddenFS []string) types.Section {
+	h := hostOf(ctx)
 	treeData := []types.TreeItem{}
 
-	// Get all partitions
-	partitions, err := disk.Partitions(false)
-	if err != nil {
-		return types.Section{
-			Name:     "Disk",
-			Expanded: false,
-			TreeData: treeData,
-			LiveData: false,
-			UseTree:  true,
-		}
-	}
+	// Get all partitions; device I/O is still shown without them
+	partitions, _ := disk.PartitionsWithContext(ctx, false)
 
 	partNum := 1
 	for _, partition := range partitions {
 		// Skip special filesystems
-		if isSpecialFS(partition.Fstype) {
+		if slices.Contains(hiddenFS, partition.Fstype) {
 			continue
 		}
 
-		usage, err := disk.Usage(partition.Mountpoint)
+		usage, err := h.Usage(ctx, h.Path(partition.Mountpoint))
 		if err != nil {
 			continue
 		}
 
 		item := types.TreeItem{
 			Name:     fmt.Sprintf("Partition %d", partNum),
-			Children: make(map[string]string),
+			Children: make(map[string]types.Field),
 			Order:    []string{},
 		}
 
-		item.Children["Mount"] = partition.Mountpoint
+		item.Children["Mount"] = types.Text(partition.Mountpoint)
 		item.Order = append(item.Order, "Mount")
 
-		item.Children["Device"] = partition.Device
+		item.Children["Device"] = types.Text(partition.Device)
 		item.Order = append(item.Order, "Device")
 
-		item.Children["FS Type"] = partition.Fstype
+		item.Children["FS Type"] = types.Text(partition.Fstype)
 		item.Order = append(item.Order, "FS Type")
 
-		item.Children["Total"] = formatBytes(usage.Total)
+		item.Children["Total"] = types.Bytes(usage.Total)
 		item.Order = append(item.Order, "Total")
 
-		item.Children["Used"] = formatBytes(usage.Used)
+		item.Children["Used"] = types.Bytes(usage.Used)
 		item.Order = append(item.Order, "Used")
 
-		item.Children["Free"] = formatBytes(usage.Free)
+		item.Children["Free"] = types.Bytes(usage.Free)
 		item.Order = append(item.Order, "Free")
 
-		item.Children["Usage"] = fmt.Sprintf("%.1f%%", usage.UsedPercent)
+		item.Children["Usage"] = types.Percent(usage.UsedPercent)
 		item.Order = append(item.Order, "Usage")
 
 		// Inodes if available
 		if usage.InodesTotal > 0 {
-			item.Children["Inodes"] = fmt.Sprintf("%d / %d", usage.InodesUsed, usage.InodesTotal)
+			item.Children["Inodes"] = types.Count(usage.InodesUsed).WithMax(float64(usage.InodesTotal))
 			item.Order = append(item.Order, "Inodes")
 		}
 
@@ -75,55 +80,208 @@ func GetDiskInfo() types.Section {
 		partNum++
 	}
 
-	// Add total disk I/O stats if available
-	if ioStats := getDiskIOStats(); ioStats != "" {
-		statsItem := types.TreeItem{
-			Name:     "I/O Statistics",
-			Children: map[string]string{"Status": ioStats},
-			Order:    []string{"Status"},
+	// Per-device I/O, averaged since boot until live mode has a baseline
+	if disks, err := readDiskStats(h); err == nil {
+		if uptime, ok := getUptime(h); ok && uptime > 0 {
+			treeData = append(treeData, diskItems(h, disks, nil, uptime)...)
 		}
-		treeData = append(treeData, statsItem)
 	}
 
 	return types.Section{
 		Name:     "Disk",
 		Expanded: false,
 		TreeData: treeData,
-		LiveData: false,
+		LiveData: true,
 		UseTree:  true,
 	}
 }
 
-func isSpecialFS(fstype string) bool {
-	specialFS := []string{
-		"tmpfs", "devtmpfs", "devpts", "sysfs", "proc",
-		"cgroup", "cgroup2", "pstore", "bpf", "tracefs",
-		"debugfs", "hugetlbfs", "mqueue", "configfs",
-		"securityfs", "fusectl", "fuse.portal",
+// sectorSize is the unit of the sector counts in /proc/diskstats, which
+// is 512 bytes whatever the device's own sector size
+const sectorSize = 512
+
+// deviceItemPrefix starts the name of the tree items describing block
+// devices, which live refreshes replace
+const deviceItemPrefix = "Device "
+
+// diskStats is the counters of one line of /proc/diskstats. Times are in
+// milliseconds.
+type diskStats struct {
+	name                            string
+	reads, readSectors, readTime    uint64
+	writes, writeSectors, writeTime uint64
+	ioTime                          uint64 // Time with requests in flight
+}
+
+// readDiskStats reads /proc/diskstats, keeping whole devices (those in
+// /sys/block) and leaving out loop and RAM devices that never did I/O
+func readDiskStats(h *Host) ([]diskStats, error) {
+	data, err := h.ReadFile("/proc/diskstats")
+	if err != nil {
+		return nil, err
 	}
 
-	for _, special := range specialFS {
-		if fstype == special {
-			return true
+	var disks []diskStats
+	for _, line := range strings.Split(string(data), "\n") {
+		// Format is "major minor name reads merged sectors ms writes
+		// merged sectors ms in-flight io-ms weighted-ms ..."
+		fields := strings.Fields(line)
+		if len(fields) < 14 {
+			continue
 		}
+		name := fields[2]
+		if !h.Exists("/sys/block/" + name) {
+			continue
+		}
+
+		var values [11]uint64
+		for i := range values {
+			values[i], _ = strconv.ParseUint(fields[3+i], 10, 64)
+		}
+		d := diskStats{
+			name:  name,
+			reads: values[0], readSectors: values[2], readTime: values[3],
+			writes: values[4], writeSectors: values[6], writeTime: values[7],
+			ioTime: values[9],
+		}
+
+		virtual := strings.HasPrefix(name, "loop") || strings.HasPrefix(name, "ram") || strings.HasPrefix(name, "zram")
+		if virtual && d.reads+d.writes == 0 {
+			continue
+		}
+		disks = append(disks, d)
 	}
+	return disks, nil
+}
 
-	return false
+// diskIO is the activity of a device over an interval
+type diskIO struct {
+	readRate, writeRate float64 // Bytes per second
+	readIOPS, writeIOPS float64
+	latency             time.Duration // Average time to serve a request
+	util                float64       // Percentage of the interval with requests in flight
 }
 
-func getDiskIOStats() string {
-	// Try to get basic I/O stats using iostat if available
-	cmd := exec.Command("iostat", "-d", "-x", "1", "1")
-	output, err := cmd.Output()
-	if err != nil {
-		return ""
+// since returns the activity between an earlier sample and d, elapsed
+// apart. Against a zero sample this is the average since boot.
+func (d diskStats) since(prev diskStats, elapsed time.Duration) diskIO {
+	delta := func(now, before uint64) float64 {
+		if now < before {
+			return 0
+		}
+		return float64(now - before)
 	}
+	seconds := elapsed.Seconds()
 
-	lines := strings.Split(string(output), "\n")
-	// Just return a summary indicator
-	if len(lines) > 0 {
-		return "Available (use iostat for details)"
+	io := diskIO{
+		readRate:  delta(d.readSectors, prev.readSectors) * sectorSize / seconds,
+		writeRate: delta(d.writeSectors, prev.writeSectors) * sectorSize / seconds,
+		readIOPS:  delta(d.reads, prev.reads) / seconds,
+		writeIOPS: delta(d.writes, prev.writes) / seconds,
+		util:      min(100, delta(d.ioTime, prev.ioTime)/(seconds*1000)*100),
+	}
+	if requests := delta(d.reads+d.writes, prev.reads+prev.writes); requests > 0 {
+		ms := delta(d.readTime+d.writeTime, prev.readTime+prev.writeTime) / requests
+		io.latency = time.Duration(ms * float64(time.Millisecond))
 	}
+	return io
+}
 
-	return ""
+// diskItems builds one tree item per device from the activity since prev,
+// elapsed ago. Devices missing from prev are measured since boot.
+func diskItems(h *Host, disks []diskStats, prev map[string]diskStats, elapsed time.Duration) []types.TreeItem {
+	items := make([]types.TreeItem, 0, len(disks))
+	for i, d := range disks {
+		item := types.TreeItem{
+			Name:     fmt.Sprintf("%s%d", deviceItemPrefix, i+1),
+			Children: make(map[string]types.Field),
+			Order:    []string{},
+		}
+		add := func(key string, value types.Field) {
+			item.Children[key] = value
+			item.Order = append(item.Order, key)
+		}
+
+		before, ok := prev[d.name]
+		interval := elapsed
+		if !ok && prev != nil {
+			if uptime, ok := getUptime(h); ok {
+				interval = uptime
+			}
+		}
+		io := d.since(before, interval)
+
+		add("Name", types.Text(d.name))
+		if data, err := h.ReadFile("/sys/block/" + d.name + "/size"); err == nil {
+			if sectors, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64); err == nil && sectors > 0 {
+				add("Size", types.Bytes(sectors*sectorSize))
+			}
+		}
+		add("Read", types.ByteRate(io.readRate))
+		add("Write", types.ByteRate(io.writeRate))
+		add("Read IOPS", types.Rate(io.readIOPS))
+		add("Write IOPS", types.Rate(io.writeIOPS))
+		add("Latency", types.Latency(io.latency))
+		add("Utilization", types.Percent(io.util))
+		add("Total Read", types.Bytes(d.readSectors*sectorSize))
+		add("Total Written", types.Bytes(d.writeSectors*sectorSize))
+
+		items = append(items, item)
+	}
+	return items
+}
+
+// diskCollector lists partitions and computes per-device I/O from the
+// change in /proc/diskstats between refreshes
+type diskCollector struct {
+	hiddenFS []string
+	stats    sampler[map[string]diskStats] // By device name
+}
+
+// NewDiskCollector creates a Disk collector that skips the given
+// filesystem types
+func NewDiskCollector(hiddenFS []string) Collector {
+	return &diskCollector{hiddenFS: hiddenFS}
+}
+
+func (c *diskCollector) Name() string            { return "Disk" }
+func (c *diskCollector) Interval() time.Duration { return DefaultInterval }
+
+func (c *diskCollector) Collect(ctx context.Context) types.Section {
+	section := GetDiskInfo(ctx, c.hiddenFS)
+	c.sample(hostOf(ctx))
+	return section
+}
+
+func (c *diskCollector) Refresh(ctx context.Context, section types.Section) types.Section {
+	h := hostOf(ctx)
+	disks, prev, elapsed, ok := c.sample(h)
+	if !ok {
+		return section
+	}
+
+	items := make([]types.TreeItem, 0, len(section.TreeData))
+	for _, item := range section.TreeData {
+		if !strings.HasPrefix(item.Name, deviceItemPrefix) {
+			items = append(items, item)
+		}
+	}
+	section.TreeData = append(items, diskItems(h, disks, prev, elapsed)...)
+	return section
+}
+
+// sample reads /proc/diskstats as the baseline of the next refresh and
+// returns it with the previous reading, by device name
+func (c *diskCollector) sample(h *Host) ([]diskStats, map[string]diskStats, time.Duration, bool) {
+	disks, err := readDiskStats(h)
+	if err != nil {
+		return nil, nil, 0, false
+	}
+
+	stats := make(map[string]diskStats, len(disks))
+	for _, d := range disks {
+		stats[d.name] = d
+	}
+	prev, elapsed, ok := c.stats.next(h, stats)
+	return disks, prev, elapsed, ok
 }
@@ -0,0 +1,52 @@
+package sysinfo
+
+import (
+	"testing"
+)
+
+func TestDiskRefreshFromDeltas(t *testing.T) {
+	host, ctx := tempHost(t, map[string]string{
+		"sys/block/sda/size":   "2048\n",
+		"sys/block/loop0/size": "0\n",
+		"proc/uptime":          "100.00 0\n",
+		"proc/diskstats": "" +
+			"   8       0 sda 1000 0 20000 500 2000 0 40000 1500 0 10000 2000 0 0 0 0\n" +
+			"   8       1 sda1 1000 0 20000 500 2000 0 40000 1500 0 10000 2000 0 0 0 0\n" +
+			"   7       0 loop0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0\n",
+	})
+	c := NewDiskCollector(nil).(*diskCollector)
+	section := c.Collect(ctx)
+	if len(section.TreeData) != 1 || section.TreeData[0].Children["Read IOPS"].String() != "10.0/s" {
+		t.Errorf("Collect = %+v, want sda averaged since boot", section.TreeData)
+	}
+
+	// Over two seconds, 100 reads of 1 MiB and 300 writes of 2 MiB took
+	// 800 ms in total, with requests in flight for 1.5 s
+	writeFiles(t, host, map[string]string{
+		"proc/uptime": "102.00 0\n",
+		"proc/diskstats": "" +
+			"   8       0 sda 1100 0 22048 700 2300 0 44096 2100 0 11500 2800 0 0 0 0\n" +
+			"   7       0 loop0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0\n",
+	})
+	section = c.Refresh(ctx, section)
+
+	if len(section.TreeData) != 1 {
+		t.Fatalf("TreeData has %d items, want only sda", len(section.TreeData))
+	}
+	want := map[string]string{
+		"Name":        "sda",
+		"Size":        "1.00 MiB",
+		"Read":        "512.00 KiB/s",
+		"Write":       "1.00 MiB/s",
+		"Read IOPS":   "50.0/s",
+		"Write IOPS":  "150.0/s",
+		"Latency":     "2.00 ms",
+		"Utilization": "75.0%",
+	}
+	item := section.TreeData[0]
+	for key, value := range want {
+		if got := item.Children[key].String(); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package sysinfo
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"peekfetch/internal/types"
+)
+
+// drmConnector matches the connectors in /sys/class/drm, such as
+// "card0-eDP-1", capturing the card and the connector name
+var drmConnector = regexp.MustCompile(`^(card\d+)-(.+)$`)
+
+// edidHeader starts every EDID base block
+var edidHeader = []byte{0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00}
+
+// pnpVendors names the manufacturers most often found in EDID data by
+// their PNP ID
+var pnpVendors = map[string]string{
+	"ACR": "Acer",
+	"AOC": "AOC",
+	"APP": "Apple",
+	"AUO": "AU Optronics",
+	"AUS": "ASUS",
+	"BNQ": "BenQ",
+	"BOE": "BOE",
+	"CMN": "Chimei Innolux",
+	"DEL": "Dell",
+	"ENC": "EIZO",
+	"GSM": "LG Electronics",
+	"HPN": "HP",
+	"HWP": "HP",
+	"IVM": "Iiyama",
+	"LEN": "Lenovo",
+	"LGD": "LG Display",
+	"MSI": "MSI",
+	"NEC": "NEC",
+	"PHL": "Philips",
+	"RHT": "Red Hat",
+	"SAM": "Samsung",
+	"SDC": "Samsung Display",
+	"SHP": "Sharp",
+	"SNY": "Sony",
+	"VSC": "ViewSonic",
+}
+
+// maxModes is how many modes of a display are listed, best first
+const maxModes = 6
+
+// displayMode is a resolution and refresh rate
+type displayMode struct {
+	width, height int
+	refresh       float64 // Hz, 0 if unknown
+}
+
+func (m displayMode) String() string {
+	if m.refresh == 0 {
+		return fmt.Sprintf("%dx%d", m.width, m.height)
+	}
+	return fmt.Sprintf("%dx%d @ %.2f Hz", m.width, m.height, m.refresh)
+}
+
+// edid is the decoded base block of a display's EDID
+type edid struct {
+	vendor            string // PNP ID, such as "DEL"
+	product           uint16
+	name              string // Monitor name descriptor, or a panel's part number
+	week, year        int
+	widthMM, heightMM int
+	native            displayMode // The preferred timing
+	modes             []displayMode
+}
+
+// parseEDID decodes the 128-byte EDID base block; extension blocks are
+// ignored
+func parseEDID(data []byte) (edid, error) {
+	var e edid
+	if len(data) < 128 || !bytes.Equal(data[:8], edidHeader) {
+		return e, errors.New("not an EDID base block")
+	}
+
+	// Three letters of five bits each, 'A' being 1
+	id := binary.BigEndian.Uint16(data[8:10])
+	e.vendor = string([]byte{
+		byte(id>>10&0x1f) + 'A' - 1,
+		byte(id>>5&0x1f) + 'A' - 1,
+		byte(id&0x1f) + 'A' - 1,
+	})
+	e.product = binary.LittleEndian.Uint16(data[10:12])
+	if week := int(data[16]); week >= 1 && week <= 54 {
+		e.week = week
+	}
+	e.year = int(data[17]) + 1990
+	e.widthMM, e.heightMM = int(data[21])*10, int(data[22])*10
+
+	var text string
+	for i := 54; i < 126; i += 18 {
+		d := data[i : i+18]
+		if d[0] != 0 || d[1] != 0 {
+			// A detailed timing; the first one is the preferred mode
+			if e.native.width != 0 {
+				continue
+			}
+			clock := float64(binary.LittleEndian.Uint16(d[0:2])) * 10e3
+			hActive := int(d[2]) | int(d[4]>>4)<<8
+			hBlank := int(d[3]) | int(d[4]&0x0f)<<8
+			vActive := int(d[5]) | int(d[7]>>4)<<8
+			vBlank := int(d[6]) | int(d[7]&0x0f)<<8
+			e.native = displayMode{hActive, vActive, 0}
+			if total := (hActive + hBlank) * (vActive + vBlank); total > 0 {
+				e.native.refresh = math.Round(clock/float64(total)*100) / 100
+			}
+			if w, h := int(d[12])|int(d[14]>>4)<<8, int(d[13])|int(d[14]&0x0f)<<8; w > 0 && h > 0 {
+				e.widthMM, e.heightMM = w, h
+			}
+			continue
+		}
+		value := strings.TrimSpace(strings.SplitN(string(d[5:]), "\n", 2)[0])
+		switch d[3] {
+		case 0xfc:
+			e.name = value
+		case 0xfe:
+			// Panels without a name carry their part number as text
+			text = value
+		}
+	}
+	if e.name == "" {
+		e.name = text
+	}
+
+	// Standard timings give the width and aspect ratio of other modes
+	for i := 38; i < 54; i += 2 {
+		if data[i] == 0x01 && data[i+1] == 0x01 || data[i] == 0 {
+			continue
+		}
+		width := (int(data[i]) + 31) * 8
+		var height int
+		switch data[i+1] >> 6 {
+		case 0:
+			height = width * 10 / 16
+		case 1:
+			height = width * 3 / 4
+		case 2:
+			height = width * 4 / 5
+		case 3:
+			height = width * 9 / 16
+		}
+		e.modes = append(e.modes, displayMode{width, height, float64(data[i+1]&0x3f + 60)})
+	}
+	return e, nil
+}
+
+// connector is a display output of a card with its attached monitor
+type connector struct {
+	name  string // Such as "eDP-1"
+	edid  edid
+	modes []string // Modes the kernel offers, best first
+}
+
+// readConnectors reads the connectors that have a display attached, with
+// its EDID when the driver exposes one
+func readConnectors(h *Host) []connector {
+	entries, err := os.ReadDir(h.Path("/sys/class/drm"))
+	if err != nil {
+		return nil
+	}
+
+	type entry struct {
+		card int
+		name string
+		dir  string
+	}
+	var found []entry
+	for _, e := range entries {
+		m := drmConnector.FindStringSubmatch(e.Name())
+		if m == nil {
+			continue
+		}
+		found = append(found, entry{sysfsIndex(m[1], "card"), m[2], "/sys/class/drm/" + e.Name()})
+	}
+	slices.SortFunc(found, func(a, b entry) int {
+		return cmp.Or(cmp.Compare(a.card, b.card), cmp.Compare(strings.ToLower(a.name), strings.ToLower(b.name)))
+	})
+
+	var connectors []connector
+	for _, e := range found {
+		if status, _ := h.ReadString(e.dir + "/status"); status != "connected" {
+			continue
+		}
+		c := connector{name: e.name}
+		if data, err := h.ReadFile(e.dir + "/edid"); err == nil {
+			c.edid, _ = parseEDID(data)
+		}
+		if data, err := h.ReadFile(e.dir + "/modes"); err == nil {
+			// A resolution is listed once per refresh rate
+			for _, mode := range strings.Fields(string(data)) {
+				if !slices.Contains(c.modes, mode) {
+					c.modes = append(c.modes, mode)
+				}
+			}
+		}
+		connectors = append(connectors, c)
+	}
+	return connectors
+}
+
+// getResolutions returns the native mode of every connected display, for
+// the System section
+func getResolutions(h *Host) string {
+	var modes []string
+	for _, c := range readConnectors(h) {
+		switch {
+		case c.edid.native.width > 0:
+			native := c.edid.native
+			modes = append(modes, fmt.Sprintf("%dx%d @ %.0f Hz", native.width, native.height, native.refresh))
+		case len(c.modes) > 0:
+			modes = append(modes, c.modes[0])
+		}
+	}
+	return strings.Join(modes, ", ")
+}
+
+// GetDisplayInfo collects the displays attached to every card's
+// connectors
+func GetDisplayInfo(ctx context.Context) types.Section {
+	h := hostOf(ctx)
+	treeData := []types.TreeItem{}
+
+	for _, c := range readConnectors(h) {
+		item := types.TreeItem{
+			Name:     c.name,
+			Children: make(map[string]types.Field),
+			Order:    []string{},
+		}
+		add := func(key string, value types.Field) {
+			item.Children[key] = value
+			item.Order = append(item.Order, key)
+		}
+
+		e := c.edid
+		if e.vendor != "" {
+			vendor := e.vendor
+			if name, ok := pnpVendors[e.vendor]; ok && name != e.vendor {
+				vendor = fmt.Sprintf("%s (%s)", name, e.vendor)
+			}
+			add("Manufacturer", types.Text(vendor))
+			model := e.name
+			if model == "" {
+				model = fmt.Sprintf("0x%04x", e.product)
+			}
+			add("Model", types.Text(model))
+			if e.week > 0 {
+				add("Manufactured", types.Text(fmt.Sprintf("%d, week %d", e.year, e.week)))
+			} else {
+				add("Manufactured", types.Text(fmt.Sprintf("%d", e.year)))
+			}
+		}
+		if e.widthMM > 0 && e.heightMM > 0 {
+			add("Width", types.Length(float64(e.widthMM)))
+			add("Height", types.Length(float64(e.heightMM)))
+			add("Size", types.Diagonal(math.Hypot(float64(e.widthMM), float64(e.heightMM))/25.4))
+		}
+		if e.native.width > 0 {
+			add("Resolution", types.Text(fmt.Sprintf("%dx%d", e.native.width, e.native.height)))
+			add("Refresh Rate", types.RefreshRate(e.native.refresh))
+		}
+
+		// The kernel's list includes modes from EDID extensions; without
+		// it, fall back to the base block's standard timings
+		modes := c.modes
+		if len(modes) == 0 {
+			for _, m := range e.modes {
+				modes = append(modes, m.String())
+			}
+		}
+		if len(modes) > maxModes {
+			modes = append(modes[:maxModes:maxModes], fmt.Sprintf("+%d more", len(modes)-maxModes))
+		}
+		if len(modes) > 0 {
+			add("Modes", types.Text(strings.Join(modes, ", ")))
+		}
+
+		treeData = append(treeData, item)
+	}
+
+	return types.Section{
+		Name:     "Displays",
+		Expanded: false,
+		TreeData: treeData,
+		LiveData: false,
+		UseTree:  true,
+	}
+}
+
+type displayCollector struct{}
+
+func (displayCollector) Name() string { return "Displays" }
+
+func (displayCollector) Collect(ctx context.Context) types.Section {
+	return GetDisplayInfo(ctx)
+}
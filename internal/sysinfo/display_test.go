@@ -0,0 +1,41 @@
+package sysinfo
+
+import (
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestParseEDID(t *testing.T) {
+	data, err := os.ReadFile("testdata/fixtures/laptop/root/sys/class/drm/card0-HDMI-A-1/edid")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := parseEDID(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.vendor != "DEL" || e.name != "DELL U2720Q" || e.year != 2021 || e.week != 14 {
+		t.Errorf("parseEDID = %s %q %d week %d, want DEL \"DELL U2720Q\" 2021 week 14", e.vendor, e.name, e.year, e.week)
+	}
+	if e.widthMM != 597 || e.heightMM != 336 {
+		t.Errorf("size = %dx%d mm, want the detailed timing's 597x336", e.widthMM, e.heightMM)
+	}
+	if got := e.native.String(); got != "3840x2160 @ 60.00 Hz" {
+		t.Errorf("native = %q, want 3840x2160 @ 60.00 Hz", got)
+	}
+
+	var modes []string
+	for _, m := range e.modes {
+		modes = append(modes, m.String())
+	}
+	want := []string{"1920x1080 @ 60.00 Hz", "1680x1050 @ 60.00 Hz", "1280x1024 @ 60.00 Hz", "1280x720 @ 60.00 Hz"}
+	if !slices.Equal(modes, want) {
+		t.Errorf("standard timings = %q, want %q", modes, want)
+	}
+
+	if _, err := parseEDID(data[:64]); err == nil {
+		t.Error("parseEDID accepted a truncated block")
+	}
+}
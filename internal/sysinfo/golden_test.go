@@ -0,0 +1,37 @@
+package sysinfo_test
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"peekfetch/internal/sysinfo"
+	"peekfetch/internal/sysinfo/sysinfotest"
+)
+
+func TestCollectorsGolden(t *testing.T) {
+	names, err := sysinfotest.Names(sysinfotest.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			host, err := sysinfotest.Load(filepath.Join(sysinfotest.Dir, name))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			registry := sysinfo.DefaultRegistry()
+			registry.SetHost(host)
+			sections := registry.CollectAll(context.Background())
+
+			got, err := json.MarshalIndent(sections, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			sysinfotest.Golden(t, filepath.Join("testdata", "golden", name+".json"), append(got, '\n'))
+		})
+	}
+}
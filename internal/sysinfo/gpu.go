@@ -0,0 +1,226 @@
+package sysinfo
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	_ "embed"
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"peekfetch/internal/types"
+)
+
+// embeddedPCIIDs is a PCI ID database trimmed to display controllers,
+// used when the system has none
+//
+//go:embed pci.ids
+var embeddedPCIIDs []byte
+
+// pciIDPaths are where distributions install the PCI ID database, usually
+// with the hwdata or pciutils package
+var pciIDPaths = []string{
+	"/usr/share/hwdata/pci.ids",
+	"/usr/share/misc/pci.ids",
+	"/usr/share/pci.ids",
+}
+
+// drmCard matches the cards in /sys/class/drm, leaving out their
+// connectors ("card0-eDP-1") and render nodes
+var drmCard = regexp.MustCompile(`^card\d+$`)
+
+// readPCIIDs returns the system's PCI ID database, or the embedded one
+func readPCIIDs(h *Host) []byte {
+	for _, path := range pciIDPaths {
+		if data, err := h.ReadFile(path); err == nil {
+			return data
+		}
+	}
+	return embeddedPCIIDs
+}
+
+// pciNames are the names of a device and its vendors in the PCI ID
+// database; any of them may be empty
+type pciNames struct {
+	vendor, device, subVendor string
+}
+
+// lookupPCI finds the names of a device and of the vendor of its subsystem,
+// the board maker, in a pci.ids database. IDs are lowercase hexadecimal.
+func lookupPCI(db []byte, vendor, device, subVendor string) pciNames {
+	var names pciNames
+	inVendor := false
+	scanner := bufio.NewScanner(bytes.NewReader(db))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		// Device classes follow the vendors
+		if strings.HasPrefix(line, "C ") {
+			break
+		}
+
+		if line[0] != '\t' {
+			id, name, _ := strings.Cut(line, "  ")
+			inVendor = id == vendor
+			if inVendor {
+				names.vendor = name
+			}
+			if id == subVendor {
+				names.subVendor = name
+			}
+			continue
+		}
+		if inVendor && !strings.HasPrefix(line, "\t\t") {
+			if id, name, _ := strings.Cut(line[1:], "  "); id == device {
+				names.device = name
+			}
+		}
+	}
+	return names
+}
+
+// pciID reads a PCI ID attribute such as "0x8086" as lowercase hexadecimal
+// without its prefix
+func pciID(h *Host, path string) string {
+	id, _ := h.ReadString(path)
+	return strings.ToLower(strings.TrimPrefix(id, "0x"))
+}
+
+// readUevent parses a sysfs uevent file into its keys
+func readUevent(h *Host, path string) map[string]string {
+	values := make(map[string]string)
+	data, err := h.ReadFile(path)
+	if err != nil {
+		return values
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if key, value, ok := strings.Cut(line, "="); ok {
+			values[key] = value
+		}
+	}
+	return values
+}
+
+// setGPULoad sets the VRAM use and busy percent of a card, for drivers
+// that expose them, such as amdgpu
+func setGPULoad(h *Host, item *types.TreeItem, card string) {
+	dir := "/sys/class/drm/" + card + "/device"
+	set := func(key string, value types.Field) {
+		if _, ok := item.Children[key]; !ok {
+			item.Order = append(item.Order, key)
+		}
+		item.Children[key] = value
+	}
+
+	total, err := h.ReadInt(dir + "/mem_info_vram_total")
+	if err == nil && total > 0 {
+		set("VRAM", types.Bytes(uint64(total)))
+		if used, err := h.ReadInt(dir + "/mem_info_vram_used"); err == nil {
+			set("VRAM Used", types.Bytes(uint64(used)).WithMax(float64(total)))
+		}
+	}
+	if busy, err := h.ReadInt(dir + "/gpu_busy_percent"); err == nil {
+		set("Busy", types.Percent(float64(busy)))
+	}
+}
+
+// GetGPUInfo collects the graphics cards in /sys/class/drm
+func GetGPUInfo(ctx context.Context) types.Section {
+	h := hostOf(ctx)
+	treeData := []types.TreeItem{}
+
+	entries, _ := os.ReadDir(h.Path("/sys/class/drm"))
+	entries = slices.DeleteFunc(entries, func(e os.DirEntry) bool {
+		return !drmCard.MatchString(e.Name())
+	})
+	slices.SortFunc(entries, func(a, b os.DirEntry) int {
+		return cmp.Compare(sysfsIndex(a.Name(), "card"), sysfsIndex(b.Name(), "card"))
+	})
+
+	var db []byte
+	for _, entry := range entries {
+		card := entry.Name()
+		dir := "/sys/class/drm/" + card + "/device"
+		item := types.TreeItem{
+			Name:     card,
+			Children: make(map[string]types.Field),
+			Order:    []string{},
+		}
+		add := func(key string, value types.Field) {
+			item.Children[key] = value
+			item.Order = append(item.Order, key)
+		}
+
+		uevent := readUevent(h, dir+"/uevent")
+		vendor, device := pciID(h, dir+"/vendor"), pciID(h, dir+"/device")
+		if slot, ok := uevent["PCI_SLOT_NAME"]; ok && vendor != "" && device != "" {
+			// The database is only read once there is a PCI card to name
+			if db == nil {
+				db = readPCIIDs(h)
+			}
+			names := lookupPCI(db, vendor, device, pciID(h, dir+"/subsystem_vendor"))
+			if names.vendor == "" {
+				names.vendor = "0x" + vendor
+			}
+			if names.device == "" {
+				names.device = "Device " + device
+			}
+			add("Vendor", types.Text(names.vendor))
+			add("Model", types.Text(names.device))
+			if names.subVendor != "" && names.subVendor != names.vendor {
+				add("Board", types.Text(names.subVendor))
+			}
+			add("PCI ID", types.Text(fmt.Sprintf("%s:%s", vendor, device)))
+			add("Bus", types.Text(slot))
+		}
+
+		if driver, ok := uevent["DRIVER"]; ok {
+			add("Driver", types.Text(driver))
+		}
+		setGPULoad(h, &item, card)
+
+		if len(item.Order) > 0 {
+			treeData = append(treeData, item)
+		}
+	}
+
+	return types.Section{
+		Name:     "GPU",
+		Expanded: false,
+		TreeData: treeData,
+		LiveData: true,
+		UseTree:  true,
+	}
+}
+
+// gpuCollector re-reads the load of every card on refresh; names and
+// drivers do not change
+type gpuCollector struct{}
+
+func (gpuCollector) Name() string            { return "GPU" }
+func (gpuCollector) Interval() time.Duration { return DefaultInterval }
+
+func (gpuCollector) Collect(ctx context.Context) types.Section {
+	return GetGPUInfo(ctx)
+}
+
+func (gpuCollector) Refresh(ctx context.Context, section types.Section) types.Section {
+	h := hostOf(ctx)
+	items := make([]types.TreeItem, 0, len(section.TreeData))
+	for _, item := range section.TreeData {
+		item.Children = maps.Clone(item.Children)
+		item.Order = slices.Clone(item.Order)
+		setGPULoad(h, &item, item.Name)
+		items = append(items, item)
+	}
+	section.TreeData = items
+	return section
+}
@@ -0,0 +1,64 @@
+package sysinfo
+
+import (
+	"testing"
+)
+
+func TestGPUNamesAndLoad(t *testing.T) {
+	dir := "sys/class/drm/card1/device/"
+	host, ctx := tempHost(t, map[string]string{
+		dir + "vendor":                    "0x1002\n",
+		dir + "device":                    "0x73BF\n",
+		dir + "subsystem_vendor":          "0x1da2\n",
+		dir + "uevent":                    "DRIVER=amdgpu\nPCI_SLOT_NAME=0000:03:00.0\n",
+		dir + "mem_info_vram_total":       "17163091968\n",
+		dir + "mem_info_vram_used":        "1073741824\n",
+		dir + "gpu_busy_percent":          "3\n",
+		"sys/class/drm/card1-DP-1/status": "connected\n",
+	})
+
+	// Without a system database the embedded copy names the card
+	section := gpuCollector{}.Collect(ctx)
+	if len(section.TreeData) != 1 {
+		t.Fatalf("TreeData = %+v, want card1 alone", section.TreeData)
+	}
+	want := map[string]string{
+		"Vendor":    "Advanced Micro Devices, Inc. [AMD/ATI]",
+		"Model":     "Navi 21 [Radeon RX 6800/6800 XT / 6900 XT]",
+		"Board":     "Sapphire Technology Limited",
+		"PCI ID":    "1002:73bf",
+		"Driver":    "amdgpu",
+		"VRAM":      "15.98 GiB",
+		"VRAM Used": "1.00 GiB",
+		"Busy":      "3.0%",
+	}
+	card := section.TreeData[0]
+	for key, value := range want {
+		if got := card.Children[key].String(); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+
+	// The load follows the card on refresh
+	writeFiles(t, host, map[string]string{dir + "gpu_busy_percent": "97\n"})
+	section = gpuCollector{}.Refresh(ctx, section)
+	if got := section.TreeData[0].Children["Busy"].String(); got != "97.0%" {
+		t.Errorf("Busy after refresh = %q, want 97.0%%", got)
+	}
+
+	// The system's database wins over the embedded one
+	writeFiles(t, host, map[string]string{
+		"usr/share/hwdata/pci.ids": "1002  AMD\n\t73bf  Navi 21\n",
+	})
+	card = gpuCollector{}.Collect(ctx).TreeData[0]
+	want = map[string]string{
+		"Vendor": "AMD",
+		"Model":  "Navi 21",
+		"Board":  "",
+	}
+	for key, value := range want {
+		if got := card.Children[key].String(); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+}
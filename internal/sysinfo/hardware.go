@@ -0,0 +1,165 @@
+package sysinfo
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"peekfetch/internal/types"
+)
+
+// dmiDir holds the firmware's description of the machine
+const dmiDir = "/sys/class/dmi/id/"
+
+// dmiGroups lays out the Hardware section: one item per group, listing
+// each field and the DMI attribute it is read from
+var dmiGroups = []struct {
+	name   string
+	fields [][2]string
+}{
+	{"System", [][2]string{
+		{"Vendor", "sys_vendor"},
+		{"Product", "product_name"},
+		{"Version", "product_version"},
+		{"Family", "product_family"},
+		{"SKU", "product_sku"},
+		{"Serial", "product_serial"},
+		{"UUID", "product_uuid"},
+	}},
+	{"Board", [][2]string{
+		{"Vendor", "board_vendor"},
+		{"Name", "board_name"},
+		{"Version", "board_version"},
+		{"Serial", "board_serial"},
+		{"Asset Tag", "board_asset_tag"},
+	}},
+	{"BIOS", [][2]string{
+		{"Vendor", "bios_vendor"},
+		{"Version", "bios_version"},
+		{"Date", "bios_date"},
+		{"Release", "bios_release"},
+	}},
+	{"Chassis", [][2]string{
+		{"Type", "chassis_type"},
+		{"Vendor", "chassis_vendor"},
+		{"Version", "chassis_version"},
+		{"Serial", "chassis_serial"},
+		{"Asset Tag", "chassis_asset_tag"},
+	}},
+}
+
+// dmiPlaceholders are values firmware leaves in fields the vendor never
+// filled in
+var dmiPlaceholders = map[string]bool{
+	"":                         true,
+	"0123456789":               true,
+	"Base Board Serial Number": true,
+	"Chassis Serial Number":    true,
+	"Default string":           true,
+	"No Asset Information":     true,
+	"None":                     true,
+	"Not Applicable":           true,
+	"Not Available":            true,
+	"Not Specified":            true,
+	"O.E.M.":                   true,
+	"System Product Name":      true,
+	"System Serial Number":     true,
+	"System Version":           true,
+	"To Be Filled By O.E.M.":   true,
+	"To be filled by O.E.M.":   true,
+	"Type1ProductConfigId":     true,
+	"Unknown":                  true,
+}
+
+// chassisTypes names the SMBIOS chassis type codes
+var chassisTypes = []string{
+	1: "Other", 2: "Unknown", 3: "Desktop", 4: "Low Profile Desktop",
+	5: "Pizza Box", 6: "Mini Tower", 7: "Tower", 8: "Portable", 9: "Laptop",
+	10: "Notebook", 11: "Hand Held", 12: "Docking Station", 13: "All in One",
+	14: "Sub Notebook", 15: "Space-saving", 16: "Lunch Box",
+	17: "Main Server Chassis", 18: "Expansion Chassis", 19: "SubChassis",
+	20: "Bus Expansion Chassis", 21: "Peripheral Chassis", 22: "RAID Chassis",
+	23: "Rack Mount", 24: "Sealed-case PC", 25: "Multi-system Chassis",
+	26: "Compact PCI", 27: "Advanced TCA", 28: "Blade", 29: "Blade Enclosure",
+	30: "Tablet", 31: "Convertible", 32: "Detachable", 33: "IoT Gateway",
+	34: "Embedded PC", 35: "Mini PC", 36: "Stick PC",
+}
+
+// chassisType names a chassis_type code, keeping unknown codes as numbers
+func chassisType(code string) string {
+	n, err := strconv.Atoi(code)
+	if err != nil || n < 0 || n >= len(chassisTypes) || chassisTypes[n] == "" {
+		return code
+	}
+	return chassisTypes[n]
+}
+
+// readDMI reads a DMI attribute. A field that is only readable by root
+// reports so, rather than being left out.
+func readDMI(h *Host, attr string) (types.Field, bool) {
+	value, err := h.ReadString(dmiDir + attr)
+	if errors.Is(err, fs.ErrPermission) {
+		return unavailable(err), true
+	}
+	if err != nil || dmiPlaceholders[value] {
+		return types.Field{}, false
+	}
+	if attr == "chassis_type" {
+		value = chassisType(value)
+	}
+	return types.Text(value), true
+}
+
+// getMachine describes the machine in one line, for the System section.
+// Some vendors, such as Lenovo, put the marketing name in the version.
+func getMachine(h *Host) string {
+	var parts []string
+	for _, attr := range []string{"product_name", "product_version"} {
+		if value, err := h.ReadString(dmiDir + attr); err == nil && !dmiPlaceholders[value] {
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// GetHardwareInfo collects the system, board, BIOS and chassis described
+// by DMI
+func GetHardwareInfo(ctx context.Context) types.Section {
+	h := hostOf(ctx)
+	treeData := []types.TreeItem{}
+
+	for _, group := range dmiGroups {
+		item := types.TreeItem{
+			Name:     group.name,
+			Children: make(map[string]types.Field),
+			Order:    []string{},
+		}
+		for _, field := range group.fields {
+			if value, ok := readDMI(h, field[1]); ok {
+				item.Children[field[0]] = value
+				item.Order = append(item.Order, field[0])
+			}
+		}
+		if len(item.Order) > 0 {
+			treeData = append(treeData, item)
+		}
+	}
+
+	return types.Section{
+		Name:     "Hardware",
+		Expanded: false,
+		TreeData: treeData,
+		LiveData: false,
+		UseTree:  true,
+	}
+}
+
+type hardwareCollector struct{}
+
+func (hardwareCollector) Name() string { return "Hardware" }
+
+func (hardwareCollector) Collect(ctx context.Context) types.Section {
+	return GetHardwareInfo(ctx)
+}
@@ -0,0 +1,176 @@
+package sysinfo
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/shirou/gopsutil/v3/common"
+	"github.com/shirou/gopsutil/v3/disk"
+	gopsutilnet "github.com/shirou/gopsutil/v3/net"
+)
+
+// Host is the machine the collectors describe. Collectors never touch
+// procfs, sysfs, the environment or external commands directly; they go
+// through the Host carried by their context, so tests can substitute a
+// captured fixture for the local machine.
+type Host struct {
+	// Root is the directory the machine's filesystem is visible at, "/"
+	// for the local machine. Every procfs, sysfs and /etc read, including
+	// those made by gopsutil, happens below it.
+	Root string
+	// Arch is the CPU architecture, as in runtime.GOARCH
+	Arch string
+	// Getenv returns the value of an environment variable
+	Getenv func(key string) string
+	// Run runs a command and returns its standard output
+	Run func(ctx context.Context, name string, args ...string) ([]byte, error)
+	// LookPath reports where an executable is installed
+	LookPath func(file string) (string, error)
+	// Hostname returns the kernel hostname, used when Root has no
+	// /etc/hostname
+	Hostname func() (string, error)
+	// Usage returns the usage of the filesystem mounted at path, which is
+	// already relocated below Root
+	Usage func(ctx context.Context, path string) (*disk.UsageStat, error)
+	// Interfaces lists the network interfaces of the local machine. Below
+	// an alternate root they are read from its files instead.
+	Interfaces func(ctx context.Context) (gopsutilnet.InterfaceStatList, error)
+	// Signal sends a signal to a process
+	Signal func(pid int, sig syscall.Signal) error
+	// SetNice sets the nice value of a process
+	SetNice func(pid, nice int) error
+	// Denied reports whether reading a file is refused, for fixtures
+	// captured without root. When nil the filesystem decides.
+	Denied func(path string) bool
+
+	// packages are the host's package counts, shared by the System and
+	// Packages sections
+	packages *packageMemo
+}
+
+// LocalHost returns the machine peekfetch is running on
+func LocalHost() *Host {
+	return &Host{
+		Root:   "/",
+		Arch:   runtime.GOARCH,
+		Getenv: os.Getenv,
+		Run: func(ctx context.Context, name string, args ...string) ([]byte, error) {
+			return exec.CommandContext(ctx, name, args...).Output()
+		},
+		LookPath:   exec.LookPath,
+		Hostname:   os.Hostname,
+		Usage:      disk.UsageWithContext,
+		Interfaces: gopsutilnet.InterfacesWithContext,
+		Signal:     syscall.Kill,
+		SetNice: func(pid, nice int) error {
+			return syscall.Setpriority(syscall.PRIO_PROCESS, pid, nice)
+		},
+	}
+}
+
+// hostKey is the context key holding the Host
+type hostKey struct{}
+
+// WithHost returns a context under which collectors describe h. For an
+// alternate root the gopsutil environment overrides are set as well, so
+// its reads follow the same root.
+func WithHost(ctx context.Context, h *Host) context.Context {
+	if h.Root != "" && h.Root != "/" {
+		env := common.EnvMap{
+			common.HostProcEnvKey: filepath.Join(h.Root, "proc"),
+			common.HostSysEnvKey:  filepath.Join(h.Root, "sys"),
+			common.HostEtcEnvKey:  filepath.Join(h.Root, "etc"),
+			common.HostVarEnvKey:  filepath.Join(h.Root, "var"),
+			common.HostRunEnvKey:  filepath.Join(h.Root, "run"),
+			common.HostDevEnvKey:  filepath.Join(h.Root, "dev"),
+			common.HostRootEnvKey: h.Root,
+		}
+		ctx = context.WithValue(ctx, common.EnvKey, env)
+	}
+	return context.WithValue(ctx, hostKey{}, h)
+}
+
+// hostOf returns the Host of ctx, defaulting to the local machine
+func hostOf(ctx context.Context) *Host {
+	if h, ok := ctx.Value(hostKey{}).(*Host); ok {
+		return h
+	}
+	return LocalHost()
+}
+
+// IsLocal reports whether the host is read at the real filesystem root
+func (h *Host) IsLocal() bool {
+	return h.Root == "" || h.Root == "/"
+}
+
+// Path returns an absolute path relocated below Root
+func (h *Host) Path(path string) string {
+	if h.IsLocal() {
+		return path
+	}
+	return filepath.Join(h.Root, path)
+}
+
+// ReadFile reads an absolute path below Root
+func (h *Host) ReadFile(path string) ([]byte, error) {
+	if h.Denied != nil && h.Denied(path) {
+		return nil, &fs.PathError{Op: "open", Path: path, Err: fs.ErrPermission}
+	}
+	return os.ReadFile(h.Path(path))
+}
+
+// Exists reports whether a path exists below Root
+func (h *Host) Exists(path string) bool {
+	_, err := os.Stat(h.Path(path))
+	return err == nil
+}
+
+// ReadString reads a single-value file below Root, such as a sysfs
+// attribute, without its trailing newline
+func (h *Host) ReadString(path string) (string, error) {
+	data, err := h.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// ReadInt reads a file below Root holding a single integer
+func (h *Host) ReadInt(path string) (int64, error) {
+	s, err := h.ReadString(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
+
+// SetHost makes every collector describe h instead of the local machine
+func (r *Registry) SetHost(h *Host) {
+	r.host = h
+}
+
+// SetRoot makes every collector read below root instead of /. It must be
+// an existing directory.
+func (r *Registry) SetRoot(root string) error {
+	info, err := os.Stat(root)
+	if err != nil {
+		return fmt.Errorf("root %s: %w", root, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("root %s: not a directory", root)
+	}
+
+	h := *r.host
+	h.Root = root
+	h.packages = nil
+	r.host = &h
+	return nil
+}
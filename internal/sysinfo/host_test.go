@@ -0,0 +1,33 @@
+package sysinfo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// tempHost returns a local host rooted at a temporary directory holding
+// files, keyed by their path below the root, and a context describing it
+func tempHost(t *testing.T, files map[string]string) (*Host, context.Context) {
+	t.Helper()
+	host := LocalHost()
+	host.Root = t.TempDir()
+	writeFiles(t, host, files)
+	return host, WithHost(context.Background(), host)
+}
+
+// writeFiles creates or replaces files below a host's root, keyed by their
+// path below it
+func writeFiles(t *testing.T, h *Host, files map[string]string) {
+	t.Helper()
+	for path, data := range files {
+		path = filepath.Join(h.Root, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
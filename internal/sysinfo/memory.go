@@ -1,7 +1,8 @@
 package sysinfo
 
 import (
-	"fmt"
+	"context"
+	"time"
 
 	"peekfetch/internal/types"
 
@@ -9,64 +10,72 @@ import (
 )
 
 // GetMemoryInfo collects detailed memory information
-func GetMemoryInfo() types.Section {
-	info := make(map[string]string)
+func GetMemoryInfo(ctx context.Context) types.Section {
+	info := make(map[string]types.Field)
 	order := []string{}
 
-	v, err := mem.VirtualMemory()
+	v, err := mem.VirtualMemoryWithContext(ctx)
 	if err == nil {
 		// Total
-		info["Total RAM"] = formatBytes(v.Total)
+		info["Total RAM"] = types.Bytes(v.Total)
 		order = append(order, "Total RAM")
 
+		// Total RAM and the rest are host-wide; a cgroup may allow less
+		if l, ok := getCgroupLimits(hostOf(ctx)); ok && l.memory > 0 && l.memory < v.Total {
+			info["Cgroup Limit"] = types.Bytes(l.memory)
+			order = append(order, "Cgroup Limit")
+		}
+
 		// Used
-		info["Used"] = formatBytes(v.Used)
+		info["Used"] = types.Bytes(v.Used)
 		order = append(order, "Used")
 
 		// Available
-		info["Available"] = formatBytes(v.Available)
+		info["Available"] = types.Bytes(v.Available)
 		order = append(order, "Available")
 
 		// Free
-		info["Free"] = formatBytes(v.Free)
+		info["Free"] = types.Bytes(v.Free)
 		order = append(order, "Free")
 
 		// Cached
 		if v.Cached > 0 {
-			info["Cached"] = formatBytes(v.Cached)
+			info["Cached"] = types.Bytes(v.Cached)
 			order = append(order, "Cached")
 		}
 
 		// Buffers
 		if v.Buffers > 0 {
-			info["Buffers"] = formatBytes(v.Buffers)
+			info["Buffers"] = types.Bytes(v.Buffers)
 			order = append(order, "Buffers")
 		}
 
 		// Shared
 		if v.Shared > 0 {
-			info["Shared"] = formatBytes(v.Shared)
+			info["Shared"] = types.Bytes(v.Shared)
 			order = append(order, "Shared")
 		}
 
 		// Usage percentage
-		info["Usage"] = fmt.Sprintf("%.1f%%", v.UsedPercent)
+		info["Usage"] = types.Percent(v.UsedPercent)
 		order = append(order, "Usage")
 	}
 
-	// Swap information
-	s, err := mem.SwapMemory()
-	if err == nil && s.Total > 0 {
-		info["Swap Total"] = formatBytes(s.Total)
+	// Swap information, from /proc/meminfo rather than the sysinfo call
+	// so that it follows the host's root
+	if err == nil && v.SwapTotal > 0 {
+		swapUsed := v.SwapTotal - v.SwapFree
+
+		info["Swap Total"] = types.Bytes(v.SwapTotal)
 		order = append(order, "Swap Total")
 
-		info["Swap Used"] = formatBytes(s.Used)
+		info["Swap Used"] = types.Bytes(swapUsed)
 		order = append(order, "Swap Used")
 
-		info["Swap Free"] = formatBytes(s.Free)
+		info["Swap Free"] = types.Bytes(v.SwapFree)
 		order = append(order, "Swap Free")
 
-		info["Swap Usage"] = fmt.Sprintf("%.1f%%", s.UsedPercent)
+		info["Swap Usage"] = types.Percent(float64(swapUsed) / float64(v.SwapTotal) * 100)
 		order = append(order, "Swap Usage")
 	}
 
@@ -79,17 +88,18 @@ func GetMemoryInfo() types.Section {
 	}
 }
 
-func formatBytes(bytes uint64) string {
-	const unit = 1024
-	if bytes < unit {
-		return fmt.Sprintf("%d B", bytes)
-	}
+type memoryCollector struct{}
 
-	div, exp := uint64(unit), 0
-	for n := bytes / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
-	}
+func (memoryCollector) Name() string            { return "Memory" }
+func (memoryCollector) Interval() time.Duration { return DefaultInterval }
+
+func (memoryCollector) Collect(ctx context.Context) types.Section {
+	return GetMemoryInfo(ctx)
+}
 
-	return fmt.Sprintf("%.2f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+func (memoryCollector) Refresh(ctx context.Context, section types.Section) types.Section {
+	updated := GetMemoryInfo(ctx)
+	section.Data = updated.Data
+	section.Order = updated.Order
+	return section
 }
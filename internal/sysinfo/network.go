@@ -1,9 +1,17 @@
 package sysinfo
 
 import (
+	"cmp"
+	"context"
+	"encoding/hex"
 	"fmt"
+	"maps"
 	"net"
+	"slices"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"peekfetch/internal/types"
 
@@ -11,16 +19,21 @@ import (
 )
 
 // GetNetworkInfo collects detailed network information
-func GetNetworkInfo() types.Section {
+func GetNetworkInfo(ctx context.Context) types.Section {
+	h := hostOf(ctx)
 	treeData := []types.TreeItem{}
 
-	interfaces, err := gopsutilnet.Interfaces()
+	// Rates are averaged since boot until live mode has a baseline
+	counters, _ := readNetCounters(ctx, h)
+	uptime, _ := getUptime(h)
+
+	interfaces, err := listInterfaces(ctx, h, counters)
 	if err != nil {
 		return types.Section{
 			Name:     "Network",
 			Expanded: false,
 			TreeData: treeData,
-			LiveData: false,
+			LiveData: true,
 			UseTree:  true,
 		}
 	}
@@ -32,41 +45,34 @@ func GetNetworkInfo() types.Section {
 			continue
 		}
 
-		// Get Go net interface for addresses
-		goIface, err := net.InterfaceByName(iface.Name)
-		if err != nil {
-			continue
-		}
-
-		addrs, err := goIface.Addrs()
-		if err != nil || len(addrs) == 0 {
+		if len(iface.Addrs) == 0 {
 			continue
 		}
 
 		item := types.TreeItem{
-			Name:     fmt.Sprintf("Interface %d", interfaceNum),
-			Children: make(map[string]string),
+			Name:     fmt.Sprintf(interfaceItemPrefix+"%d", interfaceNum),
+			Children: make(map[string]types.Field),
 			Order:    []string{},
 		}
 
-		item.Children["Name"] = iface.Name
+		item.Children["Name"] = types.Text(iface.Name)
 		item.Order = append(item.Order, "Name")
 
 		// MAC Address
 		if len(iface.HardwareAddr) > 0 {
-			item.Children["MAC"] = iface.HardwareAddr
+			item.Children["MAC"] = types.Text(iface.HardwareAddr)
 			item.Order = append(item.Order, "MAC")
 		}
 
 		// Flags/Status
 		if len(iface.Flags) > 0 {
-			item.Children["Status"] = strings.Join(iface.Flags, ", ")
+			item.Children["Status"] = types.Text(strings.Join(iface.Flags, ", "))
 			item.Order = append(item.Order, "Status")
 		}
 
 		// MTU
 		if iface.MTU > 0 {
-			item.Children["MTU"] = fmt.Sprintf("%d", iface.MTU)
+			item.Children["MTU"] = types.Number(float64(iface.MTU), types.UnitBytes)
 			item.Order = append(item.Order, "MTU")
 		}
 
@@ -74,80 +80,389 @@ func GetNetworkInfo() types.Section {
 		ipv4Found := false
 		ipv6Found := false
 
-		for _, addr := range addrs {
-			ipNet, ok := addr.(*net.IPNet)
-			if !ok {
+		for _, addr := range iface.Addrs {
+			ip, ipNet, err := net.ParseCIDR(addr.Addr)
+			if err != nil {
 				continue
 			}
 
-			if ipNet.IP.To4() != nil && !ipv4Found {
-				item.Children["IPv4"] = ipNet.IP.String()
+			if ip.To4() != nil && !ipv4Found {
+				item.Children["IPv4"] = types.Text(ip.String())
 				item.Order = append(item.Order, "IPv4")
 
 				// Subnet mask
 				maskSize, _ := ipNet.Mask.Size()
-				item.Children["Subnet"] = fmt.Sprintf("/%d", maskSize)
+				item.Children["Subnet"] = types.Text(fmt.Sprintf("/%d", maskSize))
 				item.Order = append(item.Order, "Subnet")
 
 				ipv4Found = true
-			} else if ipNet.IP.To4() == nil && !ipNet.IP.IsLoopback() && !ipv6Found {
-				item.Children["IPv6"] = ipNet.IP.String()
+			} else if ip.To4() == nil && !ip.IsLoopback() && !ipv6Found {
+				item.Children["IPv6"] = types.Text(ip.String())
 				item.Order = append(item.Order, "IPv6")
 				ipv6Found = true
 			}
 		}
 
+		if c, ok := counters[iface.Name]; ok && uptime > 0 {
+			setRates(&item, c, gopsutilnet.IOCountersStat{}, uptime)
+		}
+
 		treeData = append(treeData, item)
 		interfaceNum++
 	}
 
 	// Get network statistics as a separate tree item
-	if stats := getNetworkStats(); len(stats) > 0 {
-		statsItem := types.TreeItem{
+	if stats := getNetworkStats(counters); len(stats) > 0 {
+		treeData = append(treeData, types.TreeItem{
 			Name:     "Statistics",
 			Children: stats,
-			Order:    []string{"Total Bytes Sent", "Total Bytes Recv", "Total Packets Sent", "Total Packets Recv"},
-		}
-		if _, ok := stats["Errors"]; ok {
-			statsItem.Order = append(statsItem.Order, "Errors")
-		}
-		if _, ok := stats["Drops"]; ok {
-			statsItem.Order = append(statsItem.Order, "Drops")
-		}
-		treeData = append(treeData, statsItem)
+			Order:    statisticsOrder(stats),
+		})
 	}
 
 	return types.Section{
 		Name:     "Network",
 		Expanded: false,
 		TreeData: treeData,
-		LiveData: false,
+		LiveData: true,
 		UseTree:  true,
 	}
 }
 
-func getNetworkStats() map[string]string {
-	stats := make(map[string]string)
+// interfaceItemPrefix starts the name of the tree items describing
+// network interfaces
+const interfaceItemPrefix = "Interface "
 
-	ioCounters, err := gopsutilnet.IOCounters(false)
-	if err != nil || len(ioCounters) == 0 {
+// readNetCounters reads the counters of every interface, by name.
+// /proc/net follows the reader's network namespace, so below an alternate
+// root the host's counters are read through its init process.
+func readNetCounters(ctx context.Context, h *Host) (map[string]gopsutilnet.IOCountersStat, error) {
+	var ioCounters []gopsutilnet.IOCountersStat
+	var err error
+	if !h.IsLocal() {
+		ioCounters, err = gopsutilnet.IOCountersByFileWithContext(ctx, true, h.Path("/proc/1/net/dev"))
+	} else {
+		ioCounters, err = gopsutilnet.IOCountersWithContext(ctx, true)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	counters := make(map[string]gopsutilnet.IOCountersStat, len(ioCounters))
+	for _, c := range ioCounters {
+		counters[c.Name] = c
+	}
+	return counters, nil
+}
+
+// listInterfaces lists the network interfaces. Below an alternate root
+// they are the interfaces of the counters, read from the host's
+// /proc/1/net/dev, described by its sysfs and the address tables of its
+// init process, as gopsutil would list the reader's own instead.
+func listInterfaces(ctx context.Context, h *Host, counters map[string]gopsutilnet.IOCountersStat) (gopsutilnet.InterfaceStatList, error) {
+	if h.IsLocal() {
+		return h.Interfaces(ctx)
+	}
+	if len(counters) == 0 {
+		return nil, fmt.Errorf("no interfaces in %s", h.Path("/proc/1/net/dev"))
+	}
+
+	addrs := readIPv4Addrs(h)
+	for name, list := range readIPv6Addrs(h) {
+		addrs[name] = append(addrs[name], list...)
+	}
+
+	interfaces := make(gopsutilnet.InterfaceStatList, 0, len(counters))
+	for name := range counters {
+		dir := "/sys/class/net/" + name
+		iface := gopsutilnet.InterfaceStat{Name: name, Flags: []string{}}
+		if index, err := h.ReadInt(dir + "/ifindex"); err == nil {
+			iface.Index = int(index)
+		}
+		if mtu, err := h.ReadInt(dir + "/mtu"); err == nil {
+			iface.MTU = int(mtu)
+		}
+		if mac, err := h.ReadString(dir + "/address"); err == nil && mac != "00:00:00:00:00:00" {
+			iface.HardwareAddr = mac
+		}
+		if flags, err := h.ReadString(dir + "/flags"); err == nil {
+			carrier, _ := h.ReadString(dir + "/carrier")
+			iface.Flags = interfaceFlags(flags, carrier == "1")
+		}
+		for _, addr := range addrs[name] {
+			iface.Addrs = append(iface.Addrs, gopsutilnet.InterfaceAddr{Addr: addr})
+		}
+		interfaces = append(interfaces, iface)
+	}
+	slices.SortFunc(interfaces, func(a, b gopsutilnet.InterfaceStat) int {
+		return cmp.Or(cmp.Compare(a.Index, b.Index), strings.Compare(a.Name, b.Name))
+	})
+	return interfaces, nil
+}
+
+// interfaceFlags names the bits of a sysfs flags attribute as gopsutil
+// does. The kernel keeps running out of them, so it follows the carrier.
+func interfaceFlags(attr string, running bool) []string {
+	bits, err := strconv.ParseUint(strings.TrimPrefix(attr, "0x"), 16, 32)
+	if err != nil {
+		return []string{}
+	}
+
+	names := []struct {
+		bit  uint64
+		name string
+	}{
+		{syscall.IFF_UP, "up"},
+		{syscall.IFF_BROADCAST, "broadcast"},
+		{syscall.IFF_LOOPBACK, "loopback"},
+		{syscall.IFF_POINTOPOINT, "pointtopoint"},
+		{syscall.IFF_MULTICAST, "multicast"},
+	}
+	flags := []string{}
+	for _, n := range names {
+		if bits&n.bit != 0 {
+			flags = append(flags, n.name)
+		}
+	}
+	if running && bits&syscall.IFF_UP != 0 {
+		flags = append(flags, "running")
+	}
+	return flags
+}
+
+// readIPv4Addrs returns the IPv4 addresses of every interface in CIDR
+// notation, by interface name. fib_trie lists the local addresses without
+// their interface, so each goes to the interface of the most specific
+// directly connected route holding it, which gives its prefix length too.
+func readIPv4Addrs(h *Host) map[string][]string {
+	addrs := make(map[string][]string)
+
+	trie, err := h.ReadFile("/proc/1/net/fib_trie")
+	if err != nil {
+		return addrs
+	}
+	var local []net.IP
+	var leaf net.IP
+	for _, line := range strings.Split(string(trie), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "|-- "):
+			leaf = net.ParseIP(strings.TrimPrefix(line, "|-- ")).To4()
+		case strings.HasPrefix(line, "/32 host LOCAL") && leaf != nil:
+			if !slices.ContainsFunc(local, leaf.Equal) {
+				local = append(local, leaf)
+			}
+		}
+	}
+
+	table, err := h.ReadFile("/proc/1/net/route")
+	if err != nil {
+		return addrs
+	}
+	type route struct {
+		iface string
+		net   net.IPNet
+		ones  int
+	}
+	var routes []route
+	for _, line := range strings.Split(string(table), "\n") {
+		// Skip the header and routes through a gateway
+		fields := strings.Fields(line)
+		if len(fields) < 8 || fields[2] != "00000000" {
+			continue
+		}
+		dest, ok1 := routeIPv4(fields[1])
+		mask, ok2 := routeIPv4(fields[7])
+		if !ok1 || !ok2 {
+			continue
+		}
+		ones, _ := net.IPMask(mask).Size()
+		routes = append(routes, route{fields[0], net.IPNet{IP: dest, Mask: net.IPMask(mask)}, ones})
+	}
+
+	for _, ip := range local {
+		var best *route
+		for i, r := range routes {
+			if r.net.Contains(ip) && (best == nil || r.ones > best.ones) {
+				best = &routes[i]
+			}
+		}
+		if best != nil {
+			addrs[best.iface] = append(addrs[best.iface], fmt.Sprintf("%s/%d", ip, best.ones))
+		}
+	}
+	return addrs
+}
+
+// routeIPv4 parses an address of /proc/net/route, hexadecimal in the
+// little-endian byte order of the machines peekfetch runs on
+func routeIPv4(s string) (net.IP, bool) {
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return nil, false
+	}
+	return net.IPv4(byte(v), byte(v>>8), byte(v>>16), byte(v>>24)).To4(), true
+}
+
+// readIPv6Addrs returns the IPv6 addresses of every interface in CIDR
+// notation, by interface name
+func readIPv6Addrs(h *Host) map[string][]string {
+	addrs := make(map[string][]string)
+
+	data, err := h.ReadFile("/proc/1/net/if_inet6")
+	if err != nil {
+		return addrs
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		// Address, index, prefix length, scope, flags and name
+		fields := strings.Fields(line)
+		if len(fields) < 6 {
+			continue
+		}
+		raw, err := hex.DecodeString(fields[0])
+		prefix, err2 := strconv.ParseUint(fields[2], 16, 8)
+		if err != nil || err2 != nil || len(raw) != net.IPv6len {
+			continue
+		}
+		addrs[fields[5]] = append(addrs[fields[5]], fmt.Sprintf("%s/%d", net.IP(raw), prefix))
+	}
+	return addrs
+}
+
+// setRates sets the traffic of an interface item between an earlier
+// sample and now, elapsed apart. Against a zero sample this is the average
+// since boot.
+func setRates(item *types.TreeItem, now, prev gopsutilnet.IOCountersStat, elapsed time.Duration) {
+	seconds := elapsed.Seconds()
+	rate := func(after, before uint64) float64 {
+		if after < before {
+			return 0
+		}
+		return float64(after-before) / seconds
+	}
+
+	rates := []struct {
+		key   string
+		value types.Field
+	}{
+		{"RX", types.ByteRate(rate(now.BytesRecv, prev.BytesRecv))},
+		{"TX", types.ByteRate(rate(now.BytesSent, prev.BytesSent))},
+		{"RX Packets", types.Rate(rate(now.PacketsRecv, prev.PacketsRecv))},
+		{"TX Packets", types.Rate(rate(now.PacketsSent, prev.PacketsSent))},
+		{"RX Errors", types.Rate(rate(now.Errin, prev.Errin))},
+		{"TX Errors", types.Rate(rate(now.Errout, prev.Errout))},
+		{"RX Drops", types.Rate(rate(now.Dropin, prev.Dropin))},
+		{"TX Drops", types.Rate(rate(now.Dropout, prev.Dropout))},
+	}
+	for _, r := range rates {
+		if _, ok := item.Children[r.key]; !ok {
+			item.Order = append(item.Order, r.key)
+		}
+		item.Children[r.key] = r.value
+	}
+}
+
+// statisticsKeys are the fields of the Statistics item, in display order.
+// Errors and drops are only listed once there are some.
+var statisticsKeys = []string{
+	"Total Bytes Sent", "Total Bytes Recv", "Total Packets Sent", "Total Packets Recv",
+	"Total Errors In", "Total Errors Out", "Total Drops In", "Total Drops Out",
+}
+
+// statisticsOrder returns the Statistics fields present in stats
+func statisticsOrder(stats map[string]types.Field) []string {
+	order := []string{}
+	for _, key := range statisticsKeys {
+		if _, ok := stats[key]; ok {
+			order = append(order, key)
+		}
+	}
+	return order
+}
+
+// getNetworkStats sums the lifetime counters of every interface
+func getNetworkStats(counters map[string]gopsutilnet.IOCountersStat) map[string]types.Field {
+	stats := make(map[string]types.Field)
+	if len(counters) == 0 {
 		return stats
 	}
 
-	total := ioCounters[0]
+	var total gopsutilnet.IOCountersStat
+	for _, c := range counters {
+		total.BytesSent += c.BytesSent
+		total.BytesRecv += c.BytesRecv
+		total.PacketsSent += c.PacketsSent
+		total.PacketsRecv += c.PacketsRecv
+		total.Errin += c.Errin
+		total.Errout += c.Errout
+		total.Dropin += c.Dropin
+		total.Dropout += c.Dropout
+	}
 
-	stats["Total Bytes Sent"] = formatBytes(total.BytesSent)
-	stats["Total Bytes Recv"] = formatBytes(total.BytesRecv)
-	stats["Total Packets Sent"] = fmt.Sprintf("%d", total.PacketsSent)
-	stats["Total Packets Recv"] = fmt.Sprintf("%d", total.PacketsRecv)
+	stats["Total Bytes Sent"] = types.Bytes(total.BytesSent)
+	stats["Total Bytes Recv"] = types.Bytes(total.BytesRecv)
+	stats["Total Packets Sent"] = types.Count(total.PacketsSent)
+	stats["Total Packets Recv"] = types.Count(total.PacketsRecv)
 
 	if total.Errin > 0 || total.Errout > 0 {
-		stats["Errors"] = fmt.Sprintf("In: %d, Out: %d", total.Errin, total.Errout)
+		stats["Total Errors In"] = types.Count(total.Errin)
+		stats["Total Errors Out"] = types.Count(total.Errout)
 	}
 
 	if total.Dropin > 0 || total.Dropout > 0 {
-		stats["Drops"] = fmt.Sprintf("In: %d, Out: %d", total.Dropin, total.Dropout)
+		stats["Total Drops In"] = types.Count(total.Dropin)
+		stats["Total Drops Out"] = types.Count(total.Dropout)
 	}
 
 	return stats
 }
+
+// networkCollector computes per-interface traffic from the change in the
+// interface counters between refreshes
+type networkCollector struct {
+	counters sampler[map[string]gopsutilnet.IOCountersStat] // By interface name
+}
+
+func (c *networkCollector) Name() string            { return "Network" }
+func (c *networkCollector) Interval() time.Duration { return DefaultInterval }
+
+func (c *networkCollector) Collect(ctx context.Context) types.Section {
+	section := GetNetworkInfo(ctx)
+	h := hostOf(ctx)
+	if counters, err := readNetCounters(ctx, h); err == nil {
+		c.counters.next(h, counters)
+	}
+	return section
+}
+
+func (c *networkCollector) Refresh(ctx context.Context, section types.Section) types.Section {
+	h := hostOf(ctx)
+	counters, err := readNetCounters(ctx, h)
+	if err != nil {
+		return section
+	}
+	prev, elapsed, ok := c.counters.next(h, counters)
+	if !ok {
+		return section
+	}
+
+	items := make([]types.TreeItem, 0, len(section.TreeData))
+	for _, item := range section.TreeData {
+		item.Children = maps.Clone(item.Children)
+		item.Order = slices.Clone(item.Order)
+
+		switch {
+		case strings.HasPrefix(item.Name, interfaceItemPrefix):
+			name := item.Children["Name"].Text
+			if counter, ok := counters[name]; ok {
+				setRates(&item, counter, prev[name], elapsed)
+			}
+		case item.Name == "Statistics":
+			maps.Copy(item.Children, getNetworkStats(counters))
+			item.Order = statisticsOrder(item.Children)
+		}
+		items = append(items, item)
+	}
+	section.TreeData = items
+	return section
+}
@@ -0,0 +1,95 @@
+package sysinfo
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"peekfetch/internal/types"
+)
+
+func TestNetworkRefreshFromDeltas(t *testing.T) {
+	netDev := func(rxBytes, rxPackets, rxErrs, txBytes, txPackets, txDrop int) string {
+		return "Inter-|   Receive                                                |  Transmit\n" +
+			" face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed\n" +
+			"    lo: 5000 50 0 0 0 0 0 0 5000 50 0 0 0 0 0 0\n" +
+			fmt.Sprintf("  eth0: %d %d %d 0 0 0 0 0 %d %d 0 %d 0 0 0 0\n",
+				rxBytes, rxPackets, rxErrs, txBytes, txPackets, txDrop)
+	}
+
+	host, ctx := tempHost(t, map[string]string{
+		"proc/uptime":                "100.00 0\n",
+		"proc/1/net/dev":             netDev(1024000, 1000, 0, 512000, 500, 0),
+		"sys/class/net/eth0/ifindex": "2\n",
+		"sys/class/net/eth0/mtu":     "1500\n",
+		"sys/class/net/eth0/address": "02:42:ac:11:00:02\n",
+		"sys/class/net/eth0/flags":   "0x1003\n",
+		"sys/class/net/eth0/carrier": "1\n",
+		"proc/1/net/fib_trie": "Local:\n" +
+			"  +-- 10.0.0.0/24 2 0 2\n" +
+			"     |-- 10.0.0.2\n" +
+			"        /32 host LOCAL\n",
+		"proc/1/net/route": "Iface\tDestination\tGateway \tFlags\tRefCnt\tUse\tMetric\tMask\t\tMTU\tWindow\tIRTT\n" +
+			"eth0\t00000000\t0100000A\t0003\t0\t0\t0\t00000000\t0\t0\t0\n" +
+			"eth0\t0000000A\t00000000\t0001\t0\t0\t0\t00FFFFFF\t0\t0\t0\n",
+		"proc/1/net/if_inet6": "fe800000000000000042acfffe110002 02 40 20 80     eth0\n",
+	})
+	c := &networkCollector{}
+	section := c.Collect(ctx)
+	if !section.LiveData || len(section.TreeData) != 2 {
+		t.Fatalf("Collect = %+v, want a live eth0 item and statistics", section)
+	}
+	if got := section.TreeData[0].Children["RX"].String(); got != "10.00 KiB/s" {
+		t.Errorf("RX = %q, want eth0 averaged since boot", got)
+	}
+	described := map[string]string{
+		"MAC":    "02:42:ac:11:00:02",
+		"Status": "up, broadcast, multicast, running",
+		"MTU":    "1500",
+		"IPv4":   "10.0.0.2",
+		"Subnet": "/24",
+		"IPv6":   "fe80::42:acff:fe11:2",
+	}
+	for key, value := range described {
+		if got := section.TreeData[0].Children[key].String(); got != value {
+			t.Errorf("%s = %q, want %q from the root's sysfs and procfs", key, got, value)
+		}
+	}
+
+	// Over two seconds eth0 received 2 MiB in 300 packets, 4 of them bad,
+	// and sent 1 MiB in 100 packets, dropping 2
+	writeFiles(t, host, map[string]string{
+		"proc/uptime":    "102.00 0\n",
+		"proc/1/net/dev": netDev(1024000+2<<20, 1300, 4, 512000+1<<20, 600, 2),
+	})
+	section = c.Refresh(ctx, section)
+
+	want := map[string]string{
+		"Name":       "eth0",
+		"RX":         "1.00 MiB/s",
+		"TX":         "512.00 KiB/s",
+		"RX Packets": "150.0/s",
+		"TX Packets": "50.0/s",
+		"RX Errors":  "2.0/s",
+		"TX Errors":  "0.0/s",
+		"RX Drops":   "0.0/s",
+		"TX Drops":   "1.0/s",
+	}
+	item := section.TreeData[0]
+	for key, value := range want {
+		if got := item.Children[key].String(); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+
+	stats := section.TreeData[1]
+	if got := stats.Children["Total Packets Recv"].String(); got != "1350" {
+		t.Errorf("Total Packets Recv = %q, want lo and eth0 summed", got)
+	}
+	if got := stats.Children["Total Drops Out"]; got.Value != 2 || got.Unit != types.UnitCount {
+		t.Errorf("Total Drops Out = %+v, want a count of the new drops", got)
+	}
+	if !slices.Contains(stats.Order, "Total Drops In") {
+		t.Errorf("Statistics order = %q, want the drops listed", stats.Order)
+	}
+}
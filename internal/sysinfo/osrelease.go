@@ -0,0 +1,112 @@
+package sysinfo
+
+import (
+	"strings"
+)
+
+// osReleasePaths are where os-release(5) may be, the first taking
+// precedence
+var osReleasePaths = []string{"/etc/os-release", "/usr/lib/os-release"}
+
+// OSRelease identifies the operating system, from os-release(5)
+type OSRelease struct {
+	Name            string   // NAME, such as "Ubuntu"
+	PrettyName      string   // PRETTY_NAME, such as "Ubuntu 22.04.4 LTS"
+	ID              string   // ID, such as "ubuntu"
+	IDLike          []string // ID_LIKE: the IDs of related distributions, closest first
+	Version         string   // VERSION, such as "22.04.4 LTS (Jammy Jellyfish)"
+	VersionID       string   // VERSION_ID, such as "22.04"
+	VersionCodename string   // VERSION_CODENAME, such as "jammy"
+	BuildID         string   // BUILD_ID, such as "rolling"
+	ANSIColor       string   // ANSI_COLOR: an SGR sequence for the name, such as "0;31"
+}
+
+// String names the operating system for display, falling back from
+// PRETTY_NAME as os-release(5) describes
+func (r OSRelease) String() string {
+	switch {
+	case r.PrettyName != "":
+		return r.PrettyName
+	case r.Name != "":
+		return strings.TrimSpace(r.Name + " " + r.Version)
+	case r.ID != "":
+		return strings.TrimSpace(r.ID + " " + r.VersionID)
+	}
+	return "Linux"
+}
+
+// ReadOSRelease reads and parses os-release
+func ReadOSRelease(h *Host) (OSRelease, bool) {
+	for _, path := range osReleasePaths {
+		if data, err := h.ReadFile(path); err == nil {
+			return parseOSRelease(string(data)), true
+		}
+	}
+	return OSRelease{}, false
+}
+
+// parseOSRelease parses the environment-like assignments of os-release.
+// Unknown keys, comments and malformed lines are ignored.
+func parseOSRelease(data string) OSRelease {
+	var r OSRelease
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = unquoteOSRelease(value)
+
+		switch key {
+		case "NAME":
+			r.Name = value
+		case "PRETTY_NAME":
+			r.PrettyName = value
+		case "ID":
+			r.ID = value
+		case "ID_LIKE":
+			r.IDLike = strings.Fields(value)
+		case "VERSION":
+			r.Version = value
+		case "VERSION_ID":
+			r.VersionID = value
+		case "VERSION_CODENAME":
+			r.VersionCodename = value
+		case "BUILD_ID":
+			r.BuildID = value
+		case "ANSI_COLOR":
+			r.ANSIColor = value
+		}
+	}
+	return r
+}
+
+// unquoteOSRelease removes shell quoting from a value. Within double
+// quotes, a backslash escapes '"', '\', '$' and '`'.
+func unquoteOSRelease(value string) string {
+	if len(value) < 2 || (value[0] != '"' && value[0] != '\'') || value[len(value)-1] != value[0] {
+		return value
+	}
+	quote, value := value[0], value[1:len(value)-1]
+	if quote == '\'' {
+		return value
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) && strings.IndexByte("\"\\$`", value[i+1]) >= 0 {
+			i++
+		}
+		b.WriteByte(value[i])
+	}
+	return b.String()
+}
+
+// OSRelease identifies the operating system of the registry's host
+func (r *Registry) OSRelease() OSRelease {
+	release, _ := ReadOSRelease(r.host)
+	return release
+}
@@ -0,0 +1,41 @@
+package sysinfo
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseOSRelease(t *testing.T) {
+	r := parseOSRelease(`# Rocky Linux
+NAME="Rocky Linux"
+VERSION="9.4 (Blue Onyx)"
+ID="rocky"
+ID_LIKE="rhel centos fedora"
+VERSION_ID="9.4"
+PRETTY_NAME="Rocky Linux 9.4 (Blue Onyx)"
+ANSI_COLOR='0;32'
+SUPPORT_END=2032-05-31
+BUILD_ID="\"quoted\" \$HOME \\ \n"
+`)
+
+	if r.ID != "rocky" || r.PrettyName != "Rocky Linux 9.4 (Blue Onyx)" || r.VersionID != "9.4" {
+		t.Errorf("parseOSRelease = %+v", r)
+	}
+	if !slices.Equal(r.IDLike, []string{"rhel", "centos", "fedora"}) {
+		t.Errorf("IDLike = %q, want rhel, centos, fedora", r.IDLike)
+	}
+	if r.ANSIColor != "0;32" {
+		t.Errorf("ANSIColor = %q, want single quotes removed", r.ANSIColor)
+	}
+	// Only the four escapes of os-release are unescaped
+	if want := `"quoted" $HOME \ \n`; r.BuildID != want {
+		t.Errorf("BuildID = %q, want %q", r.BuildID, want)
+	}
+
+	if got := (OSRelease{Name: "Debian GNU/Linux", Version: "12 (bookworm)"}).String(); got != "Debian GNU/Linux 12 (bookworm)" {
+		t.Errorf("String without PRETTY_NAME = %q", got)
+	}
+	if got := (OSRelease{}).String(); got != "Linux" {
+		t.Errorf("String of an empty os-release = %q, want Linux", got)
+	}
+}
@@ -0,0 +1,113 @@
+package sysinfo
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"peekfetch/internal/types"
+)
+
+// Override customizes the output and refresh rate of a collector
+type Override struct {
+	Fields   []string      // Fields to keep, in display order; empty keeps every field
+	Interval time.Duration // Live refresh interval; zero keeps the collector's own
+}
+
+// overridden wraps a collector to apply an Override
+type overridden struct {
+	Collector
+	override Override
+}
+
+// overriddenLive is an overridden LiveCollector
+type overriddenLive struct {
+	*overridden
+	live LiveCollector
+}
+
+// Configure applies an override to the named collector. Settings left at
+// their zero value keep any earlier override.
+func (r *Registry) Configure(name string, o Override) {
+	for i, c := range r.collectors {
+		if c.Name() != name {
+			continue
+		}
+
+		base := c
+		switch existing := c.(type) {
+		case *overridden:
+			base, o = existing.Collector, merge(existing.override, o)
+		case *overriddenLive:
+			base, o = existing.live, merge(existing.override, o)
+		}
+
+		wrapped := &overridden{Collector: base, override: o}
+		if live, ok := base.(LiveCollector); ok {
+			r.collectors[i] = &overriddenLive{overridden: wrapped, live: live}
+		} else {
+			r.collectors[i] = wrapped
+		}
+		return
+	}
+}
+
+func merge(prev, next Override) Override {
+	if next.Fields == nil {
+		next.Fields = prev.Fields
+	}
+	if next.Interval == 0 {
+		next.Interval = prev.Interval
+	}
+	return next
+}
+
+func (c *overridden) Collect(ctx context.Context) types.Section {
+	return selectFields(c.Collector.Collect(ctx), c.override.Fields)
+}
+
+func (c *overriddenLive) Refresh(ctx context.Context, section types.Section) types.Section {
+	return selectFields(c.live.Refresh(ctx, section), c.override.Fields)
+}
+
+func (c *overriddenLive) Interval() time.Duration {
+	if c.override.Interval > 0 {
+		return c.override.Interval
+	}
+	return c.live.Interval()
+}
+
+// selectFields limits the display order of a section, or of every item of
+// a tree section, to the given fields. Field names match case-insensitively
+// and fields the section does not have are skipped.
+func selectFields(section types.Section, fields []string) types.Section {
+	if len(fields) == 0 {
+		return section
+	}
+
+	if section.UseTree {
+		items := make([]types.TreeItem, len(section.TreeData))
+		for i, item := range section.TreeData {
+			item.Order = selectKeys(item.Children, fields)
+			items[i] = item
+		}
+		section.TreeData = items
+	} else {
+		section.Order = selectKeys(section.Data, fields)
+	}
+
+	return section
+}
+
+func selectKeys(data map[string]types.Field, fields []string) []string {
+	order := []string{}
+	for _, field := range fields {
+		for key := range data {
+			if strings.EqualFold(key, field) {
+				order = append(order, key)
+				break
+			}
+		}
+	}
+	return order
+}
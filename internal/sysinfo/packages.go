@@ -0,0 +1,365 @@
+package sysinfo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"peekfetch/internal/types"
+)
+
+// packageManager counts the packages installed with one package manager by
+// reading its database, never by running it
+type packageManager struct {
+	name  string
+	count func(h *Host) (packageCount, bool)
+}
+
+// packageCount is how many packages a manager has installed, with the
+// other fields of its tree item in order
+type packageCount struct {
+	total   int
+	details []packageDetail
+}
+
+// packageDetail is a field of a package manager's tree item
+type packageDetail struct {
+	key   string
+	value types.Field
+}
+
+// countOf is a count field from an int
+func countOf(n int) types.Field {
+	return types.Count(uint64(n))
+}
+
+// packageManagers are counted in this order, which is also the order of
+// the System section's summary
+var packageManagers = []packageManager{
+	{"pacman", countPacman},
+	{"dpkg", countDpkg},
+	{"rpm", countRPM},
+	{"flatpak", countFlatpak},
+	{"snap", countSnap},
+	{"nix", countNix},
+	{"brew", countBrew},
+}
+
+// pacmanDB is pacman's local database, a directory per package
+const pacmanDB = "/var/lib/pacman/local"
+
+func countPacman(h *Host) (packageCount, bool) {
+	entries, err := os.ReadDir(h.Path(pacmanDB))
+	if err != nil {
+		return packageCount{}, false
+	}
+
+	total, explicit := 0, 0
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		desc, err := h.ReadFile(pacmanDB + "/" + e.Name() + "/desc")
+		if err != nil {
+			continue
+		}
+		total++
+		// Packages installed as dependencies have a reason of 1
+		if !bytes.Contains(desc, []byte("%REASON%\n1\n")) {
+			explicit++
+		}
+	}
+	return packageCount{total, []packageDetail{
+		{"Explicit", countOf(explicit)},
+		{"Dependencies", countOf(total - explicit)},
+		{"Database", types.Text(pacmanDB)},
+	}}, total > 0
+}
+
+// dpkgStatus is dpkg's database, a stanza per package it knows of
+const dpkgStatus = "/var/lib/dpkg/status"
+
+func countDpkg(h *Host) (packageCount, bool) {
+	data, err := h.ReadFile(dpkgStatus)
+	if err != nil {
+		return packageCount{}, false
+	}
+
+	// Status is "want flag state"; removed packages may keep their
+	// configuration files
+	installed, configFiles := 0, 0
+	for _, line := range strings.Split(string(data), "\n") {
+		status, ok := strings.CutPrefix(line, "Status: ")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(status)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[len(fields)-1] {
+		case "installed":
+			installed++
+		case "config-files":
+			configFiles++
+		}
+	}
+	details := []packageDetail{}
+	if configFiles > 0 {
+		details = append(details, packageDetail{"Config Files Only", countOf(configFiles)})
+	}
+	details = append(details, packageDetail{"Database", types.Text(dpkgStatus)})
+	return packageCount{installed, details}, installed > 0
+}
+
+// rpmDBPaths are where rpm keeps its SQLite database, the default since
+// rpm 4.16; older Berkeley DB databases are not read
+var rpmDBPaths = []string{"/var/lib/rpm/rpmdb.sqlite", "/usr/lib/sysimage/rpm/rpmdb.sqlite"}
+
+func countRPM(h *Host) (packageCount, bool) {
+	for _, path := range rpmDBPaths {
+		if n, err := countSQLiteRows(h, path, "Packages"); err == nil {
+			return packageCount{n, []packageDetail{{"Database", types.Text(path)}}}, n > 0
+		}
+	}
+	return packageCount{}, false
+}
+
+// countRefs counts the installed branches of flatpak refs in a directory
+// laid out as <id>/<arch>/<branch>
+func countRefs(h *Host, dir string) int {
+	count := 0
+	ids, _ := os.ReadDir(h.Path(dir))
+	for _, id := range ids {
+		// The "current" symlink beside the arches is not a directory
+		arches, _ := os.ReadDir(h.Path(dir + "/" + id.Name()))
+		for _, arch := range arches {
+			if !arch.IsDir() {
+				continue
+			}
+			branches, _ := os.ReadDir(h.Path(dir + "/" + id.Name() + "/" + arch.Name()))
+			for _, branch := range branches {
+				if branch.IsDir() {
+					count++
+				}
+			}
+		}
+	}
+	return count
+}
+
+func countFlatpak(h *Host) (packageCount, bool) {
+	installations := []string{"/var/lib/flatpak"}
+	if home := h.Getenv("HOME"); home != "" {
+		installations = append(installations, home+"/.local/share/flatpak")
+	}
+
+	apps, runtimes := 0, 0
+	var found []string
+	for _, dir := range installations {
+		a, r := countRefs(h, dir+"/app"), countRefs(h, dir+"/runtime")
+		if a+r > 0 {
+			found = append(found, dir)
+		}
+		apps += a
+		runtimes += r
+	}
+	return packageCount{apps + runtimes, []packageDetail{
+		{"Apps", countOf(apps)},
+		{"Runtimes", countOf(runtimes)},
+		{"Installations", types.Text(strings.Join(found, ", "))},
+	}}, apps+runtimes > 0
+}
+
+// snapMounts are where snaps are mounted, /snap on most distributions
+var snapMounts = []string{"/snap", "/var/lib/snapd/snap"}
+
+func countSnap(h *Host) (packageCount, bool) {
+	for _, mount := range snapMounts {
+		entries, err := os.ReadDir(h.Path(mount))
+		if err != nil {
+			continue
+		}
+		// Each snap links its active revision as "current"
+		count := 0
+		for _, e := range entries {
+			if e.IsDir() && h.Exists(mount+"/"+e.Name()+"/current") {
+				count++
+			}
+		}
+		if count > 0 {
+			return packageCount{count, []packageDetail{{"Location", types.Text(mount)}}}, true
+		}
+	}
+	return packageCount{}, false
+}
+
+// nixProfileCount counts the packages of a nix profile: the elements of
+// manifest.json for "nix profile", or the derivations of manifest.nix for
+// nix-env
+func nixProfileCount(h *Host, profile string) (int, bool) {
+	if data, err := h.ReadFile(profile + "/manifest.json"); err == nil {
+		// Elements are a list up to version 2 and keyed by name from 3
+		var manifest struct {
+			Elements json.RawMessage `json:"elements"`
+		}
+		if json.Unmarshal(data, &manifest) != nil {
+			return 0, false
+		}
+		var list []json.RawMessage
+		if json.Unmarshal(manifest.Elements, &list) == nil {
+			return len(list), true
+		}
+		var named map[string]json.RawMessage
+		if json.Unmarshal(manifest.Elements, &named) == nil {
+			return len(named), true
+		}
+		return 0, false
+	}
+	if data, err := h.ReadFile(profile + "/manifest.nix"); err == nil {
+		return strings.Count(string(data), `type = "derivation";`), true
+	}
+	return 0, false
+}
+
+func countNix(h *Host) (packageCount, bool) {
+	profiles := [][2]string{{"Default Profile", "/nix/var/nix/profiles/default"}}
+	if home := h.Getenv("HOME"); home != "" {
+		// ~/.nix-profile links to the XDG location on newer installs
+		user := home + "/.nix-profile"
+		if !h.Exists(user) {
+			user = home + "/.local/state/nix/profile"
+		}
+		profiles = append(profiles, [2]string{"User Profile", user})
+	}
+
+	total := 0
+	details := []packageDetail{}
+	for _, profile := range profiles {
+		if n, ok := nixProfileCount(h, profile[1]); ok {
+			total += n
+			details = append(details, packageDetail{profile[0], countOf(n)})
+		}
+	}
+	return packageCount{total, details}, total > 0
+}
+
+// brewPrefixes are where Homebrew on Linux is installed, system-wide or
+// for one user
+var brewPrefixes = []string{"/home/linuxbrew/.linuxbrew"}
+
+func countBrew(h *Host) (packageCount, bool) {
+	prefixes := brewPrefixes
+	if home := h.Getenv("HOME"); home != "" {
+		prefixes = append(prefixes[:len(prefixes):len(prefixes)], home+"/.linuxbrew")
+	}
+
+	for _, prefix := range prefixes {
+		formulae, err := os.ReadDir(h.Path(prefix + "/Cellar"))
+		if err != nil {
+			continue
+		}
+		casks, _ := os.ReadDir(h.Path(prefix + "/Caskroom"))
+		total := len(formulae) + len(casks)
+		details := []packageDetail{{"Formulae", countOf(len(formulae))}}
+		if len(casks) > 0 {
+			details = append(details, packageDetail{"Casks", countOf(len(casks))})
+		}
+		details = append(details, packageDetail{"Prefix", types.Text(prefix)})
+		return packageCount{total, details}, total > 0
+	}
+	return packageCount{}, false
+}
+
+// countedPackages is a package manager found on the host with its count
+type countedPackages struct {
+	manager string
+	packageCount
+}
+
+// countPackages counts the packages of every manager found on the host
+func countPackages(h *Host) []countedPackages {
+	var counts []countedPackages
+	for _, pm := range packageManagers {
+		if count, ok := pm.count(h); ok {
+			counts = append(counts, countedPackages{pm.name, count})
+		}
+	}
+	return counts
+}
+
+// packageMemo holds a host's package counts once they are first needed
+type packageMemo struct {
+	once   sync.Once
+	counts []countedPackages
+}
+
+// packageMemos guards the creation of the memo of each host
+var packageMemos sync.Mutex
+
+// hostPackages counts a host's packages the first time either section
+// asks and shares the result with the other, since reading every package
+// manager's database is slow and neither section is live
+func hostPackages(h *Host) []countedPackages {
+	packageMemos.Lock()
+	if h.packages == nil {
+		h.packages = &packageMemo{}
+	}
+	memo := h.packages
+	packageMemos.Unlock()
+
+	memo.once.Do(func() {
+		memo.counts = countPackages(h)
+	})
+	return memo.counts
+}
+
+// getPackages summarizes the package counts for the System section, such
+// as "1423 (pacman), 12 (flatpak)"
+func getPackages(h *Host) string {
+	var parts []string
+	for _, c := range hostPackages(h) {
+		parts = append(parts, fmt.Sprintf("%d (%s)", c.total, c.manager))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// GetPackagesInfo collects the packages installed with each package
+// manager
+func GetPackagesInfo(ctx context.Context) types.Section {
+	h := hostOf(ctx)
+	treeData := []types.TreeItem{}
+
+	for _, c := range hostPackages(h) {
+		item := types.TreeItem{
+			Name:     c.manager,
+			Children: map[string]types.Field{"Packages": countOf(c.total)},
+			Order:    []string{"Packages"},
+		}
+		for _, detail := range c.details {
+			item.Children[detail.key] = detail.value
+			item.Order = append(item.Order, detail.key)
+		}
+		treeData = append(treeData, item)
+	}
+
+	return types.Section{
+		Name:     "Packages",
+		Expanded: false,
+		TreeData: treeData,
+		LiveData: false,
+		UseTree:  true,
+	}
+}
+
+type packagesCollector struct{}
+
+func (packagesCollector) Name() string { return "Packages" }
+
+func (packagesCollector) Collect(ctx context.Context) types.Section {
+	return GetPackagesInfo(ctx)
+}
@@ -0,0 +1,70 @@
+package sysinfo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCountRPM(t *testing.T) {
+	// 151 packages on 512-byte pages, so the table has interior pages and
+	// the last package's blob spills to overflow pages
+	db, err := os.ReadFile(filepath.Join("testdata", "rpmdb.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, _ := tempHost(t, map[string]string{"usr/lib/sysimage/rpm/rpmdb.sqlite": string(db)})
+
+	count, ok := countRPM(host)
+	if !ok || count.total != 151 {
+		t.Errorf("countRPM = %d, %v, want 151", count.total, ok)
+	}
+	if _, err := countSQLiteRows(host, "/usr/lib/sysimage/rpm/rpmdb.sqlite", "Basenames"); err == nil {
+		t.Error("countSQLiteRows found a table that does not exist")
+	}
+
+	// Anything but an SQLite database is not counted
+	writeFiles(t, host, map[string]string{"var/lib/rpm/rpmdb.sqlite": "Berkeley DB is not read"})
+	if _, err := countSQLiteRows(host, "/var/lib/rpm/rpmdb.sqlite", "Packages"); err == nil {
+		t.Error("countSQLiteRows read a file that is not an SQLite database")
+	}
+}
+
+func TestCountDpkg(t *testing.T) {
+	host, _ := tempHost(t, map[string]string{
+		"var/lib/dpkg/status": "Package: bash\nStatus: install ok installed\n\n" +
+			"Package: vim\nStatus: hold ok installed\n\n" +
+			"Package: apache2\nStatus: deinstall ok config-files\n\n" +
+			"Package: nano\nStatus: purge ok not-installed\n",
+	})
+	count, ok := countDpkg(host)
+	if !ok || count.total != 2 {
+		t.Fatalf("countDpkg = %d, %v, want 2 installed", count.total, ok)
+	}
+	if d := count.details[0]; d.key != "Config Files Only" || d.value.String() != "1" {
+		t.Errorf("first detail = %s %s, want 1 with config files only", d.key, d.value)
+	}
+}
+
+func TestPackagesCountedOnce(t *testing.T) {
+	host, ctx := tempHost(t, map[string]string{
+		"var/lib/dpkg/status": "Package: bash\nStatus: install ok installed\n",
+	})
+	reads := 0
+	host.Denied = func(path string) bool {
+		if path == dpkgStatus {
+			reads++
+		}
+		return false
+	}
+
+	if got := getPackages(host); got != "1 (dpkg)" {
+		t.Errorf("getPackages = %q, want 1 (dpkg)", got)
+	}
+	if section := GetPackagesInfo(ctx); len(section.TreeData) != 1 {
+		t.Errorf("TreeData = %+v, want dpkg alone", section.TreeData)
+	}
+	if reads != 1 {
+		t.Errorf("dpkg status read %d times, want once for both sections", reads)
+	}
+}
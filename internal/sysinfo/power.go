@@ -0,0 +1,199 @@
+package sysinfo
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"peekfetch/internal/types"
+)
+
+// PowerInterval is how often power supplies are re-read in live mode.
+// Batteries update their readings every few seconds at most.
+const PowerInterval = 2 * DefaultInterval
+
+// battery holds the readings of one battery, with energies in watt-hours
+// and power in watts
+type battery struct {
+	status                        string
+	capacity                      float64 // Percent, -1 if unknown
+	energyNow, energyFull, design float64
+	power                         float64
+	cycles                        int64 // -1 if unknown
+	manufacturer, model, tech     string
+}
+
+// readBattery reads the battery in dir. Batteries report either energy in
+// µWh and power in µW, or charge in µAh and current in µA, which are
+// converted using the voltage.
+func readBattery(h *Host, dir string) battery {
+	b := battery{capacity: -1, cycles: -1}
+	b.status, _ = h.ReadString(dir + "/status")
+	b.manufacturer, _ = h.ReadString(dir + "/manufacturer")
+	b.model, _ = h.ReadString(dir + "/model_name")
+	b.tech, _ = h.ReadString(dir + "/technology")
+	if v, err := h.ReadInt(dir + "/capacity"); err == nil {
+		b.capacity = float64(v)
+	}
+	if v, err := h.ReadInt(dir + "/cycle_count"); err == nil && v > 0 {
+		b.cycles = v
+	}
+
+	micro := func(name string) float64 {
+		v, err := h.ReadInt(dir + "/" + name)
+		if err != nil || v < 0 {
+			return 0
+		}
+		return float64(v) / 1e6
+	}
+
+	if _, err := h.ReadInt(dir + "/energy_now"); err == nil {
+		b.energyNow = micro("energy_now")
+		b.energyFull = micro("energy_full")
+		b.design = micro("energy_full_design")
+		b.power = micro("power_now")
+	} else {
+		// Charge is rated at the design voltage; current flows at the
+		// present one
+		volts := micro("voltage_min_design")
+		if volts == 0 {
+			volts = micro("voltage_now")
+		}
+		b.energyNow = micro("charge_now") * volts
+		b.energyFull = micro("charge_full") * volts
+		b.design = micro("charge_full_design") * volts
+		b.power = micro("current_now") * micro("voltage_now")
+	}
+
+	if b.capacity < 0 && b.energyFull > 0 {
+		b.capacity = b.energyNow / b.energyFull * 100
+	}
+	return b
+}
+
+// estimate returns the time until the battery is empty or full at the
+// present power draw, and the field it is shown as
+func (b battery) estimate() (string, time.Duration, bool) {
+	if b.power <= 0 {
+		return "", 0, false
+	}
+	switch b.status {
+	case "Discharging":
+		hours := b.energyNow / b.power
+		return "Time to Empty", time.Duration(hours * float64(time.Hour)), true
+	case "Charging":
+		if b.energyFull <= b.energyNow {
+			return "", 0, false
+		}
+		hours := (b.energyFull - b.energyNow) / b.power
+		return "Time to Full", time.Duration(hours * float64(time.Hour)), true
+	}
+	return "", 0, false
+}
+
+// batteryItem describes a battery as a tree item
+func batteryItem(name string, b battery) types.TreeItem {
+	item := types.TreeItem{
+		Name:     name,
+		Children: make(map[string]types.Field),
+		Order:    []string{},
+	}
+	add := func(key string, value types.Field) {
+		item.Children[key] = value
+		item.Order = append(item.Order, key)
+	}
+
+	if b.capacity >= 0 {
+		add("Charge", types.Percent(b.capacity))
+	}
+	if b.status != "" {
+		add("Status", types.Text(b.status))
+	}
+	if b.energyFull > 0 {
+		add("Energy", types.Energy(b.energyNow))
+		add("Energy Full", types.Energy(b.energyFull))
+	}
+	if b.design > 0 {
+		add("Energy Design", types.Energy(b.design))
+		if b.energyFull > 0 {
+			add("Health", types.Percent(min(100, b.energyFull/b.design*100)))
+		}
+	}
+	if b.cycles >= 0 {
+		add("Cycle Count", types.Count(uint64(b.cycles)))
+	}
+	if b.status == "Charging" || b.status == "Discharging" {
+		add("Power", types.Power(b.power))
+	}
+	if key, d, ok := b.estimate(); ok {
+		add(key, types.Duration(d))
+	}
+	if b.manufacturer != "" {
+		add("Manufacturer", types.Text(b.manufacturer))
+	}
+	if b.model != "" {
+		add("Model", types.Text(b.model))
+	}
+	if b.tech != "" {
+		add("Technology", types.Text(b.tech))
+	}
+	return item
+}
+
+// GetPowerInfo collects the batteries and adapters in
+// /sys/class/power_supply
+func GetPowerInfo(ctx context.Context) types.Section {
+	h := hostOf(ctx)
+	treeData := []types.TreeItem{}
+
+	entries, _ := os.ReadDir(h.Path("/sys/class/power_supply"))
+	for _, entry := range entries {
+		dir := "/sys/class/power_supply/" + entry.Name()
+		kind, _ := h.ReadString(dir + "/type")
+
+		if kind == "Battery" {
+			treeData = append(treeData, batteryItem(entry.Name(), readBattery(h, dir)))
+			continue
+		}
+
+		// Mains and USB adapters only report whether they supply power
+		online, err := h.ReadInt(dir + "/online")
+		if err != nil {
+			continue
+		}
+		state := "No"
+		if online == 1 {
+			state = "Yes"
+		}
+		item := types.TreeItem{
+			Name:     entry.Name(),
+			Children: map[string]types.Field{"Type": types.Text(kind), "Online": types.Text(state)},
+			Order:    []string{"Type", "Online"},
+		}
+		treeData = append(treeData, item)
+	}
+
+	return types.Section{
+		Name:     "Power",
+		Expanded: false,
+		TreeData: treeData,
+		LiveData: true,
+		UseTree:  true,
+	}
+}
+
+// powerCollector re-reads every power supply on refresh, so charge and
+// estimates follow the battery
+type powerCollector struct{}
+
+func (powerCollector) Name() string            { return "Power" }
+func (powerCollector) Interval() time.Duration { return PowerInterval }
+
+func (powerCollector) Collect(ctx context.Context) types.Section {
+	return GetPowerInfo(ctx)
+}
+
+func (powerCollector) Refresh(ctx context.Context, section types.Section) types.Section {
+	section.TreeData = GetPowerInfo(ctx).TreeData
+	return section
+}
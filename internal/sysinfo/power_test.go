@@ -0,0 +1,61 @@
+package sysinfo
+
+import (
+	"testing"
+)
+
+func TestPowerFromChargeReadings(t *testing.T) {
+	// A 4 Ah battery rated at 10 V, half full and charging at 1 A and 10 V
+	dir := "sys/class/power_supply/BAT1/"
+	host, ctx := tempHost(t, map[string]string{
+		dir + "type":                         "Battery\n",
+		dir + "status":                       "Charging\n",
+		dir + "charge_now":                   "2000000\n",
+		dir + "charge_full":                  "4000000\n",
+		dir + "charge_full_design":           "5000000\n",
+		dir + "current_now":                  "1000000\n",
+		dir + "voltage_now":                  "10000000\n",
+		dir + "voltage_min_design":           "10000000\n",
+		"sys/class/power_supply/ADP1/type":   "Mains\n",
+		"sys/class/power_supply/ADP1/online": "1\n",
+	})
+
+	c := powerCollector{}
+	section := c.Collect(ctx)
+	if len(section.TreeData) != 2 {
+		t.Fatalf("TreeData = %+v, want an adapter and a battery", section.TreeData)
+	}
+	if got := section.TreeData[0].Children["Online"].String(); got != "Yes" {
+		t.Errorf("ADP1 Online = %q, want Yes", got)
+	}
+
+	want := map[string]string{
+		"Charge":        "50.0%",
+		"Energy":        "20.00 Wh",
+		"Energy Full":   "40.00 Wh",
+		"Energy Design": "50.00 Wh",
+		"Health":        "80.0%",
+		"Power":         "10.00 W",
+		"Time to Full":  "2h",
+	}
+	battery := section.TreeData[1]
+	for key, value := range want {
+		if got := battery.Children[key].String(); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+
+	// Unplugged, the estimate turns around on the next refresh
+	writeFiles(t, host, map[string]string{
+		dir + "status":      "Discharging\n",
+		dir + "current_now": "500000\n",
+	})
+	section = c.Refresh(ctx, section)
+	battery = section.TreeData[1]
+	if _, ok := battery.Children["Time to Full"]; ok {
+		t.Error("Time to Full shown while discharging")
+	}
+	if got := battery.Children["Time to Empty"].String(); got != "4h" {
+		t.Errorf("Time to Empty = %q, want 4h", got)
+	}
+}
@@ -0,0 +1,507 @@
+package sysinfo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"peekfetch/internal/types"
+)
+
+// ProcessInterval is the live refresh interval of the process list, which
+// is slower than the other collectors since it reads every /proc entry
+const ProcessInterval = 2 * DefaultInterval
+
+// Process table columns, in display order
+var processColumns = []types.Column{
+	{Name: "PID", Width: 7},
+	{Name: "User", Width: 10},
+	{Name: "CPU%", Width: 6, Desc: true},
+	{Name: "RSS", Width: 10, Desc: true},
+	{Name: "NI", Width: 3},
+	{Name: "S", Width: 2},
+	{Name: "Command"},
+}
+
+// process is one entry of /proc
+type process struct {
+	pid     int
+	ppid    int
+	uid     int
+	name    string
+	state   string // One letter, as in ps
+	command string
+	rss     uint64 // Bytes
+	nice    int
+	ticks   uint64 // User and system time, in jiffies
+}
+
+// readProcesses reads every process of /proc, ordered by PID
+func readProcesses(h *Host) ([]process, error) {
+	entries, err := os.ReadDir(h.Path("/proc"))
+	if err != nil {
+		return nil, err
+	}
+
+	procs := []process{}
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil || !e.IsDir() {
+			continue
+		}
+		// Processes may exit while the list is read
+		if p, err := readProcess(h, pid); err == nil {
+			procs = append(procs, p)
+		}
+	}
+
+	sort.Slice(procs, func(i, j int) bool { return procs[i].pid < procs[j].pid })
+	return procs, nil
+}
+
+// readProcess reads a process from /proc/PID/stat, status and cmdline
+func readProcess(h *Host, pid int) (process, error) {
+	dir := fmt.Sprintf("/proc/%d", pid)
+	p := process{pid: pid}
+
+	stat, err := h.ReadFile(dir + "/stat")
+	if err != nil {
+		return p, err
+	}
+
+	// The command name is in parentheses and may itself contain spaces
+	// and parentheses, so the fields start after the last ")"
+	s := string(stat)
+	open, end := strings.IndexByte(s, '('), strings.LastIndexByte(s, ')')
+	if open < 0 || end < open {
+		return p, fmt.Errorf("%s/stat: malformed", dir)
+	}
+	p.name = s[open+1 : end]
+	fields := strings.Fields(s[end+1:])
+	if len(fields) < 17 {
+		return p, fmt.Errorf("%s/stat: too few fields", dir)
+	}
+
+	// fields[0] is field 3 of proc(5): state, ppid, ..., utime (14),
+	// stime (15), ..., nice (19)
+	p.state = fields[0]
+	p.ppid, _ = strconv.Atoi(fields[1])
+	utime, _ := strconv.ParseUint(fields[11], 10, 64)
+	stime, _ := strconv.ParseUint(fields[12], 10, 64)
+	p.ticks = utime + stime
+	p.nice, _ = strconv.Atoi(fields[16])
+
+	if status, err := h.ReadFile(dir + "/status"); err == nil {
+		for _, line := range strings.Split(string(status), "\n") {
+			key, value, ok := strings.Cut(line, ":")
+			if !ok {
+				continue
+			}
+			values := strings.Fields(value)
+			if len(values) == 0 {
+				continue
+			}
+			switch key {
+			case "Uid":
+				p.uid, _ = strconv.Atoi(values[0])
+			case "VmRSS":
+				kb, _ := strconv.ParseUint(values[0], 10, 64)
+				p.rss = kb * 1024
+			}
+		}
+	}
+
+	// Kernel threads have an empty command line
+	if cmdline, err := h.ReadFile(dir + "/cmdline"); err == nil && len(cmdline) > 0 {
+		p.command = strings.TrimSpace(strings.ReplaceAll(string(cmdline), "\x00", " "))
+	}
+	if p.command == "" {
+		p.command = "[" + p.name + "]"
+	}
+
+	return p, nil
+}
+
+// readUsers maps user IDs to names from /etc/passwd
+func readUsers(h *Host) map[int]string {
+	users := make(map[int]string)
+
+	data, err := h.ReadFile("/etc/passwd")
+	if err != nil {
+		return users
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Split(line, ":")
+		if len(fields) < 3 {
+			continue
+		}
+		if uid, err := strconv.Atoi(fields[2]); err == nil {
+			users[uid] = fields[0]
+		}
+	}
+	return users
+}
+
+// processTable builds the table rows, with CPU% from the tick deltas
+// since prev spread over elapsed jiffies of a single CPU
+func processTable(procs []process, users map[int]string, prev map[int]uint64, elapsed float64) *types.Table {
+	table := &types.Table{Columns: processColumns, Processes: true}
+
+	for _, p := range procs {
+		user, ok := users[p.uid]
+		if !ok {
+			user = strconv.Itoa(p.uid)
+		}
+
+		cpu := 0.0
+		if before, ok := prev[p.pid]; ok && elapsed > 0 && p.ticks >= before {
+			cpu = float64(p.ticks-before) / elapsed * 100
+		}
+
+		table.Rows = append(table.Rows, types.Row{
+			ID:     strconv.Itoa(p.pid),
+			Parent: strconv.Itoa(p.ppid),
+			Cells: []types.Field{
+				types.Count(uint64(p.pid)),
+				types.Text(user),
+				types.Percent(cpu),
+				types.Bytes(p.rss),
+				types.Number(float64(p.nice), types.UnitCount),
+				types.Text(p.state),
+				types.Text(p.command),
+			},
+		})
+	}
+
+	return table
+}
+
+// processCollector lists processes, computing CPU% from the change in
+// their tick counters between refreshes
+type processCollector struct {
+	mu        sync.Mutex
+	prevTicks map[int]uint64
+	prevTotal uint64 // Aggregate CPU jiffies at the previous sample
+}
+
+func (c *processCollector) Name() string            { return "Processes" }
+func (c *processCollector) Interval() time.Duration { return ProcessInterval }
+
+func (c *processCollector) Collect(ctx context.Context) types.Section {
+	return c.sample(ctx)
+}
+
+func (c *processCollector) Refresh(ctx context.Context, section types.Section) types.Section {
+	refreshed := c.sample(ctx)
+	section.Table = refreshed.Table
+	section.Data = refreshed.Data
+	section.Order = refreshed.Order
+	return section
+}
+
+// sample reads the process list and advances the CPU% baseline
+func (c *processCollector) sample(ctx context.Context) types.Section {
+	h := hostOf(ctx)
+	section := types.Section{Name: "Processes", LiveData: true}
+
+	procs, err := readProcesses(h)
+	if err != nil {
+		section.Data = map[string]types.Field{"Status": types.Text(fmt.Sprintf("Unavailable (%v)", err))}
+		section.Order = []string{"Status"}
+		return section
+	}
+
+	// Jiffies that passed on one CPU, so a process using a whole core
+	// shows 100%
+	var total uint64
+	cpus := 1
+	if stat, err := readCPUStat(h); err == nil {
+		total = stat.all.total()
+		cpus = max(1, len(stat.cpus))
+	}
+
+	c.mu.Lock()
+	elapsed := 0.0
+	if c.prevTicks != nil && total > c.prevTotal {
+		elapsed = float64(total-c.prevTotal) / float64(cpus)
+	}
+	section.Table = processTable(procs, readUsers(h), c.prevTicks, elapsed)
+
+	c.prevTicks = make(map[int]uint64, len(procs))
+	for _, p := range procs {
+		c.prevTicks[p.pid] = p.ticks
+	}
+	c.prevTotal = total
+	c.mu.Unlock()
+
+	return section
+}
+
+// ErrNotLocal is returned when signaling or renicing a process listed
+// below an alternate root. Its PIDs belong to the target's PID namespace,
+// so the same number may be an unrelated process of peekfetch's own.
+var ErrNotLocal = errors.New("processes below an alternate root cannot be signaled or reniced")
+
+// ControlsProcesses reports whether the listed processes can be signaled
+// and reniced, which is only so on the local machine
+func (r *Registry) ControlsProcesses() bool {
+	return r.host.IsLocal()
+}
+
+// SignalProcess sends sig to a process of the described machine
+func (r *Registry) SignalProcess(pid int, sig syscall.Signal) error {
+	if !r.ControlsProcesses() {
+		return ErrNotLocal
+	}
+	return r.host.Signal(pid, sig)
+}
+
+// ReniceProcess changes the nice value of a process by delta, within the
+// kernel's -20 to 19 range, and returns the new value
+func (r *Registry) ReniceProcess(pid, delta int) (int, error) {
+	if !r.ControlsProcesses() {
+		return 0, ErrNotLocal
+	}
+	p, err := readProcess(r.host, pid)
+	if err != nil {
+		return 0, err
+	}
+	nice := min(19, max(-20, p.nice+delta))
+	return nice, r.host.SetNice(pid, nice)
+}
+
+// Namespaces listed in the process details, as named in /proc/PID/ns
+var processNamespaces = []string{"cgroup", "ipc", "mnt", "net", "pid", "time", "user", "uts"}
+
+// I/O counters of /proc/PID/io, in display order
+var processIO = []struct {
+	key   string
+	label string
+	bytes bool
+}{
+	{"rchar", "Chars Read", true},
+	{"wchar", "Chars Written", true},
+	{"syscr", "Read Calls", false},
+	{"syscw", "Write Calls", false},
+	{"read_bytes", "Storage Read", true},
+	{"write_bytes", "Storage Written", true},
+	{"cancelled_write_bytes", "Cancelled Writes", true},
+}
+
+// ProcessDetails describes one process of the described machine as a tree
+// section with Process, Namespaces, Soft Limits, Hard Limits and I/O items. Files of other
+// users' processes that need privileges are reported as such.
+func (r *Registry) ProcessDetails(pid int) (types.Section, error) {
+	h := r.host
+	p, err := readProcess(h, pid)
+	if err != nil {
+		return types.Section{}, fmt.Errorf("process %d: %w", pid, err)
+	}
+	dir := fmt.Sprintf("/proc/%d", pid)
+
+	info := types.TreeItem{Name: "Process", Children: make(map[string]types.Field)}
+	add := func(item *types.TreeItem, key string, value types.Field) {
+		item.Children[key] = value
+		item.Order = append(item.Order, key)
+	}
+
+	add(&info, "Command Line", types.Text(p.command))
+	add(&info, "Parent PID", types.Count(uint64(p.ppid)))
+
+	if cwd, err := os.Readlink(h.Path(dir + "/cwd")); err == nil {
+		add(&info, "Working Dir", types.Text(cwd))
+	} else {
+		add(&info, "Working Dir", unavailable(err))
+	}
+
+	if environ, err := h.ReadFile(dir + "/environ"); err == nil {
+		add(&info, "Environment", types.Count(uint64(strings.Count(string(environ), "\x00"))))
+	} else {
+		add(&info, "Environment", unavailable(err))
+	}
+
+	if fds, err := os.ReadDir(h.Path(dir + "/fd")); err == nil {
+		add(&info, "Open Files", types.Count(uint64(len(fds))))
+	} else {
+		add(&info, "Open Files", unavailable(err))
+	}
+
+	if cgroup, err := h.ReadFile(dir + "/cgroup"); err == nil {
+		add(&info, "Cgroup", types.Text(cgroupPath(string(cgroup))))
+	} else {
+		add(&info, "Cgroup", unavailable(err))
+	}
+
+	namespaces := types.TreeItem{Name: "Namespaces", Children: make(map[string]types.Field)}
+	for _, ns := range processNamespaces {
+		link, err := os.Readlink(h.Path(dir + "/ns/" + ns))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			add(&namespaces, ns, unavailable(err))
+			continue
+		}
+		// Links read "net:[4026531840]"; the inode identifies the namespace
+		inode := strings.TrimSuffix(strings.TrimPrefix(link, ns+":["), "]")
+		add(&namespaces, ns, types.Text(inode))
+	}
+
+	soft := types.TreeItem{Name: "Soft Limits", Children: make(map[string]types.Field)}
+	hard := types.TreeItem{Name: "Hard Limits", Children: make(map[string]types.Field)}
+	if data, err := h.ReadFile(dir + "/limits"); err == nil {
+		for _, limit := range parseLimits(string(data)) {
+			add(&soft, limit.name, limit.soft)
+			add(&hard, limit.name, limit.hard)
+		}
+	} else {
+		add(&soft, "Status", unavailable(err))
+	}
+
+	io := types.TreeItem{Name: "I/O", Children: make(map[string]types.Field)}
+	if data, err := h.ReadFile(dir + "/io"); err == nil {
+		counters := make(map[string]uint64)
+		for _, line := range strings.Split(string(data), "\n") {
+			key, value, ok := strings.Cut(line, ":")
+			if !ok {
+				continue
+			}
+			if n, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64); err == nil {
+				counters[key] = n
+			}
+		}
+		for _, c := range processIO {
+			n, ok := counters[c.key]
+			if !ok {
+				continue
+			}
+			if c.bytes {
+				add(&io, c.label, types.Bytes(n))
+			} else {
+				add(&io, c.label, types.Count(n))
+			}
+		}
+	} else {
+		add(&io, "Status", unavailable(err))
+	}
+
+	items := []types.TreeItem{info}
+	for _, item := range []types.TreeItem{namespaces, soft, hard, io} {
+		if len(item.Order) > 0 {
+			items = append(items, item)
+		}
+	}
+
+	return types.Section{
+		Name:     fmt.Sprintf("PID %d (%s)", pid, p.name),
+		TreeData: items,
+		UseTree:  true,
+	}, nil
+}
+
+// unavailable describes why a process file could not be read
+func unavailable(err error) types.Field {
+	if errors.Is(err, fs.ErrPermission) {
+		return types.Text("Permission denied (requires root)")
+	}
+	return types.Text("N/A")
+}
+
+// cgroupPath returns the cgroup v2 path of a /proc/PID/cgroup file, or
+// the v1 controller paths that are not the root on v1 and hybrid systems
+func cgroupPath(data string) string {
+	unified := ""
+	var v1 []string
+	for _, line := range strings.Split(strings.TrimSpace(data), "\n") {
+		// Lines are "hierarchy-ID:controllers:path"
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if parts[0] == "0" && parts[1] == "" {
+			unified = parts[2]
+			continue
+		}
+		if parts[2] != "/" {
+			v1 = append(v1, parts[1]+":"+parts[2])
+		}
+	}
+
+	if len(v1) == 0 {
+		if unified == "" {
+			return "/"
+		}
+		return unified
+	}
+	return strings.Join(v1, ", ")
+}
+
+// processLimit is one resource limit of /proc/PID/limits
+type processLimit struct {
+	name       string
+	soft, hard types.Field
+}
+
+// parseLimits reads /proc/PID/limits, whose columns are aligned to fixed
+// widths since limit names contain spaces
+func parseLimits(data string) []processLimit {
+	const nameWidth = 26
+
+	var limits []processLimit
+	for _, line := range strings.Split(data, "\n") {
+		if len(line) <= nameWidth || strings.HasPrefix(line, "Limit ") {
+			continue
+		}
+		fields := strings.Fields(line[nameWidth:])
+		if len(fields) < 2 {
+			continue
+		}
+
+		name := strings.TrimPrefix(strings.TrimSpace(line[:nameWidth]), "Max ")
+		if name == "" {
+			continue
+		}
+		name = strings.ToUpper(name[:1]) + name[1:]
+		name = strings.Replace(name, "Cpu ", "CPU ", 1)
+
+		units := ""
+		if len(fields) > 2 {
+			units = fields[2]
+		}
+		limits = append(limits, processLimit{
+			name: name,
+			soft: limitValue(fields[0], units),
+			hard: limitValue(fields[1], units),
+		})
+	}
+	return limits
+}
+
+// limitValue types one limit of /proc/PID/limits by its units. Limits
+// without units, such as priorities, and counts of files, processes,
+// locks or signals are plain counts.
+func limitValue(v, units string) types.Field {
+	n, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		// "unlimited"
+		return types.Text(v)
+	}
+	switch units {
+	case "bytes":
+		return types.Bytes(n)
+	case "seconds":
+		return types.Duration(time.Duration(n) * time.Second)
+	case "us":
+		return types.Latency(time.Duration(n) * time.Microsecond)
+	}
+	return types.Count(n)
+}
@@ -0,0 +1,150 @@
+package sysinfo
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+)
+
+func TestProcessCPUFromDeltas(t *testing.T) {
+	host, ctx := tempHost(t, map[string]string{
+		"etc/passwd":      "root:x:0:0::/root:/bin/sh\nalice:x:1000:1000::/home/alice:/bin/sh\n",
+		"proc/42/status":  "Name:\tmy (odd) cmd\nUid:\t1000\t1000\t1000\t1000\nVmRSS:\t    2048 kB\n",
+		"proc/42/cmdline": "worker\x00--fast\x00",
+	})
+	writeTicks := func(total, utime, stime int) {
+		t.Helper()
+		writeFiles(t, host, map[string]string{
+			"proc/stat":    "cpu  " + strconv.Itoa(total) + " 0 0 0 0 0 0 0 0 0\ncpu0 1 0 0 0\ncpu1 1 0 0 0\n",
+			"proc/42/stat": "42 (my (odd) cmd) R 1 42 42 0 -1 0 0 0 0 0 " + strconv.Itoa(utime) + " " + strconv.Itoa(stime) + " 0 0 20 5 1 0 0 0 0\n",
+		})
+	}
+
+	writeTicks(1000, 100, 100)
+	c := &processCollector{}
+	section := c.Collect(ctx)
+
+	// 200 jiffies passed on two CPUs, of which the process used 100: one
+	// whole CPU
+	writeTicks(1200, 160, 140)
+	section = c.Refresh(ctx, section)
+
+	if section.Table == nil || len(section.Table.Rows) != 1 {
+		t.Fatalf("Table = %+v, want one row", section.Table)
+	}
+	row := section.Table.Rows[0]
+	want := []string{"42", "alice", "100.0%", "2.00 MiB", "5", "R", "worker --fast"}
+	for i, cell := range row.Cells {
+		if got := cell.String(); got != want[i] {
+			t.Errorf("%s = %q, want %q", section.Table.Columns[i].Name, got, want[i])
+		}
+	}
+	if got := row.Cells[2].Value; math.Abs(got-100) > 1e-9 {
+		t.Errorf("CPU%% = %v, want 100", got)
+	}
+}
+
+func TestProcessDetails(t *testing.T) {
+	host, _ := tempHost(t, map[string]string{
+		"proc/7/stat":    "7 (server) S 1 7 7 0 -1 0 0 0 0 0 10 5 0 0 20 0 1 0 0 0 0\n",
+		"proc/7/cmdline": "/app/server\x00--listen\x00:8080\x00",
+		"proc/7/environ": "PATH=/bin\x00HOME=/root\x00",
+		"proc/7/fd/0":    "",
+		"proc/7/fd/1":    "",
+		"proc/7/cgroup":  "0::/system.slice/app.service\n",
+		"proc/7/limits": "Limit                     Soft Limit           Hard Limit           Units     \n" +
+			"Max cpu time              unlimited            unlimited            seconds   \n" +
+			"Max stack size            8388608              unlimited            bytes     \n" +
+			"Max open files            1024                 524288               files     \n" +
+			"                          16                   16                   slots     \n",
+		"proc/7/io": "rchar: 2048\nwchar: 10\nsyscr: 3\nsyscw: 1\nread_bytes: 4096\nwrite_bytes: 0\ncancelled_write_bytes: 0\n",
+	})
+	for path, target := range map[string]string{"proc/7/cwd": "/srv/app", "proc/7/ns/net": "net:[4026531840]"} {
+		path = filepath.Join(host.Root, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(target, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := NewRegistry()
+	r.SetHost(host)
+
+	section, err := r.ProcessDetails(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]string)
+	for _, item := range section.TreeData {
+		for _, key := range item.Order {
+			got[item.Name+"/"+key] = item.Children[key].String()
+		}
+	}
+	want := map[string]string{
+		"Process/Command Line":   "/app/server --listen :8080",
+		"Process/Working Dir":    "/srv/app",
+		"Process/Environment":    "2",
+		"Process/Open Files":     "2",
+		"Process/Cgroup":         "/system.slice/app.service",
+		"Namespaces/net":         "4026531840",
+		"Soft Limits/CPU time":   "unlimited",
+		"Soft Limits/Stack size": "8.00 MiB",
+		"Hard Limits/Stack size": "unlimited",
+		"Soft Limits/Open files": "1024",
+		"Hard Limits/Open files": "524288",
+		"I/O/Chars Read":         "2.00 KiB",
+		"I/O/Read Calls":         "3",
+		"I/O/Storage Read":       "4.00 KiB",
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s = %q, want %q", key, got[key], value)
+		}
+	}
+	for _, item := range section.TreeData {
+		if item.Name == "Soft Limits" && item.Children["Stack size"].Value != 8<<20 {
+			t.Errorf("Soft stack size = %+v, want 8 MiB in bytes", item.Children["Stack size"])
+		}
+	}
+	if value, ok := got["Soft Limits/"]; ok {
+		t.Errorf("limit without a name = %q, want it skipped", value)
+	}
+	if section.Name != "PID 7 (server)" {
+		t.Errorf("Name = %q", section.Name)
+	}
+
+	if _, err := r.ProcessDetails(8); err == nil {
+		t.Error("ProcessDetails of a missing process succeeded")
+	}
+}
+
+func TestProcessActionsRefusedBelowRoot(t *testing.T) {
+	signaled := false
+	host, _ := tempHost(t, nil)
+	host.Signal = func(int, syscall.Signal) error {
+		signaled = true
+		return nil
+	}
+	host.SetNice = func(int, int) error {
+		signaled = true
+		return nil
+	}
+	registry := NewRegistry()
+	registry.SetHost(host)
+
+	if err := registry.SignalProcess(1, syscall.SIGTERM); !errors.Is(err, ErrNotLocal) {
+		t.Errorf("SignalProcess = %v, want ErrNotLocal", err)
+	}
+	if _, err := registry.ReniceProcess(1, 1); !errors.Is(err, ErrNotLocal) {
+		t.Errorf("ReniceProcess = %v, want ErrNotLocal", err)
+	}
+	if signaled {
+		t.Error("a local process was signaled or reniced")
+	}
+}
@@ -0,0 +1,251 @@
+package sysinfo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"peekfetch/internal/types"
+)
+
+// DefaultInterval is the live refresh interval of the built-in collectors
+const DefaultInterval = 500 * time.Millisecond
+
+// DefaultTimeout bounds how long a single collector may run
+const DefaultTimeout = 3 * time.Second
+
+// snapshotWindow is the minimum time between collecting and refreshing in
+// Snapshot, so that live values computed from counter deltas have a
+// meaningful sampling window
+const snapshotWindow = 200 * time.Millisecond
+
+// Collector gathers one section of system information
+type Collector interface {
+	// Name is the section name shown in the UI
+	Name() string
+	// Collect gathers the full section, giving up when ctx is done
+	Collect(ctx context.Context) types.Section
+}
+
+// LiveCollector is a Collector whose section can be refreshed in live mode
+type LiveCollector interface {
+	Collector
+	// Refresh updates the live fields of a previously collected section
+	Refresh(ctx context.Context, section types.Section) types.Section
+	// Interval is how often Refresh should run in live mode
+	Interval() time.Duration
+}
+
+// sampler keeps the last reading of a live collector's counters and the
+// uptime it was taken at, so that each refresh measures the change since
+// the one before
+type sampler[T any] struct {
+	mu     sync.Mutex
+	prev   T
+	uptime time.Duration // When prev was read, 0 before the first reading
+}
+
+// next stores a reading as the baseline of the next refresh and returns
+// the previous one with the time elapsed since. It reports false when
+// there is no previous reading or no time has passed, in which case the
+// refresh keeps the values it has.
+func (s *sampler[T]) next(h *Host, reading T) (prev T, elapsed time.Duration, ok bool) {
+	now, ok := getUptime(h)
+	if !ok {
+		return prev, 0, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev, then := s.prev, s.uptime
+	s.prev, s.uptime = reading, now
+	return prev, now - then, then > 0 && now > then
+}
+
+// Registry holds the enabled collectors in display order
+type Registry struct {
+	collectors []Collector
+	timeout    time.Duration
+	host       *Host
+}
+
+// NewRegistry creates a registry from collectors in display order
+func NewRegistry(collectors ...Collector) *Registry {
+	return &Registry{collectors: collectors, timeout: DefaultTimeout, host: LocalHost()}
+}
+
+// DefaultRegistry returns a registry with every built-in collector
+func DefaultRegistry() *Registry {
+	return NewRegistry(
+		systemCollector{},
+		hardwareCollector{},
+		&cpuCollector{},
+		gpuCollector{},
+		displayCollector{},
+		memoryCollector{},
+		&limitsCollector{},
+		NewDiskCollector(DefaultHiddenFilesystems),
+		&networkCollector{},
+		sensorsCollector{},
+		powerCollector{},
+		packagesCollector{},
+		&processCollector{},
+	)
+}
+
+// Register appends a collector, replacing any collector with the same name
+func (r *Registry) Register(c Collector) {
+	for i, existing := range r.collectors {
+		if existing.Name() == c.Name() {
+			r.collectors[i] = c
+			return
+		}
+	}
+	r.collectors = append(r.collectors, c)
+}
+
+// Disable removes the named collector, if registered
+func (r *Registry) Disable(name string) {
+	for i, c := range r.collectors {
+		if c.Name() == name {
+			r.collectors = append(r.collectors[:i], r.collectors[i+1:]...)
+			return
+		}
+	}
+}
+
+// Reorder moves the named collectors to the front in the given order.
+// Collectors that are not named keep their relative order after them.
+func (r *Registry) Reorder(names []string) {
+	ordered := make([]Collector, 0, len(r.collectors))
+	used := make(map[string]bool)
+
+	for _, name := range names {
+		if c, ok := r.Lookup(name); ok && !used[name] {
+			ordered = append(ordered, c)
+			used[name] = true
+		}
+	}
+	for _, c := range r.collectors {
+		if !used[c.Name()] {
+			ordered = append(ordered, c)
+		}
+	}
+
+	r.collectors = ordered
+}
+
+// Lookup returns the named collector
+func (r *Registry) Lookup(name string) (Collector, bool) {
+	for _, c := range r.collectors {
+		if c.Name() == name {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// Names returns the names of the enabled collectors in display order
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.collectors))
+	for _, c := range r.collectors {
+		names = append(names, c.Name())
+	}
+	return names
+}
+
+// Collectors returns the enabled collectors in display order
+func (r *Registry) Collectors() []Collector {
+	return append([]Collector(nil), r.collectors...)
+}
+
+// SetTimeout sets how long each collector may run before it is abandoned
+func (r *Registry) SetTimeout(timeout time.Duration) {
+	r.timeout = timeout
+}
+
+// Collect runs a single collector, bounded by the registry timeout. A
+// collector that does not finish in time yields a section noting the
+// timeout rather than blocking the caller.
+func (r *Registry) Collect(ctx context.Context, c Collector) types.Section {
+	section, err := r.run(ctx, c.Collect)
+	if err != nil {
+		return types.Section{
+			Name:  c.Name(),
+			Data:  map[string]types.Field{"Status": types.Text(fmt.Sprintf("Unavailable (%v)", err))},
+			Order: []string{"Status"},
+		}
+	}
+	return section
+}
+
+// Refresh runs a live collector's refresh, bounded by the registry timeout.
+// On timeout the previous section is returned unchanged.
+func (r *Registry) Refresh(ctx context.Context, c LiveCollector, section types.Section) types.Section {
+	refreshed, err := r.run(ctx, func(ctx context.Context) types.Section {
+		return c.Refresh(ctx, section)
+	})
+	if err != nil {
+		return section
+	}
+	return refreshed
+}
+
+// run calls fn in the background and waits at most the registry timeout
+// for it to return
+func (r *Registry) run(ctx context.Context, fn func(context.Context) types.Section) (types.Section, error) {
+	ctx, cancel := context.WithTimeout(WithHost(ctx, r.host), r.timeout)
+	defer cancel()
+
+	done := make(chan types.Section, 1)
+	go func() {
+		done <- fn(ctx)
+	}()
+
+	select {
+	case section := <-done:
+		return section, nil
+	case <-ctx.Done():
+		return types.Section{}, ctx.Err()
+	}
+}
+
+// CollectAll gathers every section concurrently and returns them in
+// display order
+func (r *Registry) CollectAll(ctx context.Context) []types.Section {
+	sections := make([]types.Section, len(r.collectors))
+
+	var wg sync.WaitGroup
+	for i, c := range r.collectors {
+		wg.Add(1)
+		go func(i int, c Collector) {
+			defer wg.Done()
+			sections[i] = r.Collect(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	return sections
+}
+
+// Snapshot gathers every section and refreshes live collectors once, so
+// that fields such as CPU usage hold a real reading instead of a placeholder
+func (r *Registry) Snapshot(ctx context.Context) []types.Section {
+	start := time.Now()
+	sections := r.CollectAll(ctx)
+
+	if wait := snapshotWindow - time.Since(start); wait > 0 {
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+		}
+	}
+
+	for i, c := range r.collectors {
+		if live, ok := c.(LiveCollector); ok {
+			sections[i] = r.Refresh(ctx, live, sections[i])
+		}
+	}
+	return sections
+}
@@ -0,0 +1,305 @@
+package sysinfo
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"peekfetch/internal/types"
+)
+
+// SensorInterval is how often sensors are re-read in live mode. Readings
+// change slowly and some chips are slow to query.
+const SensorInterval = 2 * DefaultInterval
+
+// sensorChannel matches the reading files of a hwmon chip, such as
+// "temp1_input" or "power1_average"
+var sensorChannel = regexp.MustCompile(`^(temp|fan|in|power)(\d+)_(input|average)$`)
+
+// sensorKinds orders the channels of a chip by kind
+var sensorKinds = []string{"temp", "fan", "in", "power"}
+
+// cpuTempDrivers are the hwmon drivers that measure the CPU, with the
+// labels of their package-wide sensor, best first. Without one of those
+// labels the chip's first temperature is used.
+var cpuTempDrivers = map[string][]string{
+	"coretemp":    {"Package id 0"},
+	"k10temp":     {"Tdie", "Tctl"},
+	"zenpower":    {"Tdie", "Tctl"},
+	"cpu_thermal": nil,
+}
+
+// sensorChip is one hwmon chip and its readings
+type sensorChip struct {
+	name     string // Driver name, such as "coretemp"
+	labels   []string
+	readings map[string]types.Field // By label
+}
+
+// readSensorChips reads every hwmon chip in the order the kernel numbered
+// them
+func readSensorChips(h *Host) []sensorChip {
+	entries, err := os.ReadDir(h.Path("/sys/class/hwmon"))
+	if err != nil {
+		return nil
+	}
+	slices.SortFunc(entries, func(a, b os.DirEntry) int {
+		return cmp.Compare(sysfsIndex(a.Name(), "hwmon"), sysfsIndex(b.Name(), "hwmon"))
+	})
+
+	var chips []sensorChip
+	for _, entry := range entries {
+		dir := "/sys/class/hwmon/" + entry.Name()
+		chip := readSensorChip(h, dir)
+		if len(chip.labels) > 0 {
+			chips = append(chips, chip)
+		}
+	}
+	return chips
+}
+
+// readSensorChip reads the temperatures, fans, voltages and power readings
+// of the hwmon chip in dir
+func readSensorChip(h *Host, dir string) sensorChip {
+	chip := sensorChip{readings: make(map[string]types.Field)}
+	chip.name, _ = h.ReadString(dir + "/name")
+	if chip.name == "" {
+		chip.name = dir[strings.LastIndex(dir, "/")+1:]
+	}
+
+	files, err := os.ReadDir(h.Path(dir))
+	if err != nil {
+		return chip
+	}
+
+	type channel struct {
+		kind  string
+		index int
+		file  string
+	}
+	var channels []channel
+	seen := make(map[string]bool)
+	for _, file := range files {
+		m := sensorChannel.FindStringSubmatch(file.Name())
+		if m == nil {
+			continue
+		}
+		// Power meters may report both an instant and an average reading
+		prefix := m[1] + m[2]
+		if seen[prefix] {
+			continue
+		}
+		seen[prefix] = true
+		index, _ := strconv.Atoi(m[2])
+		channels = append(channels, channel{m[1], index, file.Name()})
+	}
+	slices.SortFunc(channels, func(a, b channel) int {
+		return cmp.Or(
+			cmp.Compare(slices.Index(sensorKinds, a.kind), slices.Index(sensorKinds, b.kind)),
+			cmp.Compare(a.index, b.index),
+		)
+	})
+
+	for _, c := range channels {
+		prefix := fmt.Sprintf("%s/%s%d_", dir, c.kind, c.index)
+		raw, err := h.ReadInt(dir + "/" + c.file)
+		if err != nil {
+			continue
+		}
+
+		var value types.Field
+		var fallback string
+		switch c.kind {
+		case "temp":
+			value = types.Temperature(float64(raw) / 1000)
+			// Bound by the critical threshold, or the maximum without one
+			for _, limit := range []string{"crit", "max"} {
+				if v, err := h.ReadInt(prefix + limit); err == nil && v > 0 {
+					value = value.WithMax(float64(v) / 1000)
+					break
+				}
+			}
+			fallback = fmt.Sprintf("Temp %d", c.index)
+		case "fan":
+			value = types.FanSpeed(float64(raw))
+			if v, err := h.ReadInt(prefix + "max"); err == nil && v > 0 {
+				value = value.WithMax(float64(v))
+			}
+			fallback = fmt.Sprintf("Fan %d", c.index)
+		case "in":
+			value = types.Voltage(float64(raw) / 1000)
+			fallback = fmt.Sprintf("Voltage %d", c.index)
+		case "power":
+			value = types.Power(float64(raw) / 1e6)
+			for _, limit := range []string{"cap", "max"} {
+				if v, err := h.ReadInt(prefix + limit); err == nil && v > 0 {
+					value = value.WithMax(float64(v) / 1e6)
+					break
+				}
+			}
+			fallback = fmt.Sprintf("Power %d", c.index)
+		}
+
+		label, _ := h.ReadString(prefix + "label")
+		if label == "" {
+			label = fallback
+		}
+		label = uniqueLabel(chip.readings, label)
+		chip.readings[label] = value
+		chip.labels = append(chip.labels, label)
+	}
+	return chip
+}
+
+// readThermalZones reads the temperature of every thermal zone, labelled
+// by zone type and bound by the zone's critical trip point
+func readThermalZones(h *Host) ([]string, map[string]types.Field) {
+	entries, err := os.ReadDir(h.Path("/sys/class/thermal"))
+	if err != nil {
+		return nil, nil
+	}
+	slices.SortFunc(entries, func(a, b os.DirEntry) int {
+		return cmp.Compare(sysfsIndex(a.Name(), "thermal_zone"), sysfsIndex(b.Name(), "thermal_zone"))
+	})
+
+	var labels []string
+	readings := make(map[string]types.Field)
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), "thermal_zone") {
+			continue
+		}
+		dir := "/sys/class/thermal/" + entry.Name()
+		raw, err := h.ReadInt(dir + "/temp")
+		if err != nil {
+			continue
+		}
+
+		value := types.Temperature(float64(raw) / 1000)
+		for trip := 0; ; trip++ {
+			kind, err := h.ReadString(fmt.Sprintf("%s/trip_point_%d_type", dir, trip))
+			if err != nil {
+				break
+			}
+			if kind != "critical" {
+				continue
+			}
+			if v, err := h.ReadInt(fmt.Sprintf("%s/trip_point_%d_temp", dir, trip)); err == nil && v > 0 {
+				value = value.WithMax(float64(v) / 1000)
+			}
+			break
+		}
+
+		label, _ := h.ReadString(dir + "/type")
+		if label == "" {
+			label = entry.Name()
+		}
+		label = uniqueLabel(readings, label)
+		readings[label] = value
+		labels = append(labels, label)
+	}
+	return labels, readings
+}
+
+// getCPUTemperature returns the package temperature reported by a CPU
+// driver. Other chips, such as ACPI zones or drives, are never used because
+// they do not measure the CPU.
+func getCPUTemperature(h *Host) (types.Field, bool) {
+	for _, chip := range readSensorChips(h) {
+		preferred, ok := cpuTempDrivers[chip.name]
+		if !ok {
+			continue
+		}
+		for _, label := range preferred {
+			if value, ok := chip.readings[label]; ok {
+				return value, true
+			}
+		}
+		for _, label := range chip.labels {
+			if value := chip.readings[label]; value.Kind == types.KindTemperature {
+				return value, true
+			}
+		}
+	}
+	return types.Field{}, false
+}
+
+// GetSensorsInfo collects every hwmon chip and thermal zone
+func GetSensorsInfo(ctx context.Context) types.Section {
+	h := hostOf(ctx)
+	treeData := []types.TreeItem{}
+
+	names := make(map[string]int)
+	for _, chip := range readSensorChips(h) {
+		// Chips of the same driver, such as one per socket or drive, are
+		// numbered from the second on
+		name := chip.name
+		if names[chip.name]++; names[chip.name] > 1 {
+			name = fmt.Sprintf("%s %d", chip.name, names[chip.name])
+		}
+		treeData = append(treeData, types.TreeItem{
+			Name:     name,
+			Children: chip.readings,
+			Order:    chip.labels,
+		})
+	}
+
+	if labels, readings := readThermalZones(h); len(labels) > 0 {
+		treeData = append(treeData, types.TreeItem{
+			Name:     "Thermal Zones",
+			Children: readings,
+			Order:    labels,
+		})
+	}
+
+	return types.Section{
+		Name:     "Sensors",
+		Expanded: false,
+		TreeData: treeData,
+		LiveData: true,
+		UseTree:  true,
+	}
+}
+
+// sysfsIndex returns the number ending a sysfs entry such as "hwmon3", so
+// that "hwmon10" sorts after "hwmon9"
+func sysfsIndex(name, prefix string) int {
+	n, err := strconv.Atoi(strings.TrimPrefix(name, prefix))
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// uniqueLabel returns label, numbered when readings already has it
+func uniqueLabel(readings map[string]types.Field, label string) string {
+	if _, ok := readings[label]; !ok {
+		return label
+	}
+	for n := 2; ; n++ {
+		next := fmt.Sprintf("%s %d", label, n)
+		if _, ok := readings[next]; !ok {
+			return next
+		}
+	}
+}
+
+// sensorsCollector re-reads every sensor on refresh
+type sensorsCollector struct{}
+
+func (sensorsCollector) Name() string            { return "Sensors" }
+func (sensorsCollector) Interval() time.Duration { return SensorInterval }
+
+func (sensorsCollector) Collect(ctx context.Context) types.Section {
+	return GetSensorsInfo(ctx)
+}
+
+func (sensorsCollector) Refresh(ctx context.Context, section types.Section) types.Section {
+	section.TreeData = GetSensorsInfo(ctx).TreeData
+	return section
+}
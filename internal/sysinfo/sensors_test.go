@@ -0,0 +1,41 @@
+package sysinfo
+
+import (
+	"testing"
+)
+
+func TestCPUTemperatureFromCPUDriver(t *testing.T) {
+	// An ACPI zone and a drive come first but do not measure the CPU
+	host, _ := tempHost(t, map[string]string{
+		"sys/class/thermal/thermal_zone0/temp": "27800\n",
+		"sys/class/hwmon/hwmon0/name":          "acpitz\n",
+		"sys/class/hwmon/hwmon0/temp1_input":   "27800\n",
+		"sys/class/hwmon/hwmon1/name":          "nvme\n",
+		"sys/class/hwmon/hwmon1/temp1_input":   "38850\n",
+	})
+	if _, ok := getCPUTemperature(host); ok {
+		t.Error("getCPUTemperature found a temperature without a CPU driver")
+	}
+
+	// k10temp's die temperature is preferred over its control value
+	writeFiles(t, host, map[string]string{
+		"sys/class/hwmon/hwmon10/name":        "k10temp\n",
+		"sys/class/hwmon/hwmon10/temp1_input": "71500\n",
+		"sys/class/hwmon/hwmon10/temp1_label": "Tctl\n",
+		"sys/class/hwmon/hwmon10/temp2_input": "61500\n",
+		"sys/class/hwmon/hwmon10/temp2_label": "Tdie\n",
+		"sys/class/hwmon/hwmon10/temp2_crit":  "95000\n",
+	})
+	value, ok := getCPUTemperature(host)
+	if !ok || value.String() != "61.5°C / 95.0°C" {
+		t.Errorf("getCPUTemperature = %q, %v, want Tdie", value.String(), ok)
+	}
+
+	chips := readSensorChips(host)
+	if len(chips) != 3 || chips[2].name != "k10temp" {
+		t.Fatalf("readSensorChips = %+v, want hwmon10 last", chips)
+	}
+	if got := chips[0].labels; len(got) != 1 || got[0] != "Temp 1" {
+		t.Errorf("unlabelled channel = %q, want Temp 1", got)
+	}
+}
@@ -0,0 +1,290 @@
+package sysinfo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// sqliteHeader starts every SQLite 3 database file
+var sqliteHeader = []byte("SQLite format 3\x00")
+
+// sqliteMaxDepth bounds how deep a b-tree is walked
+const sqliteMaxDepth = 32
+
+// sqliteMaxPayload is the largest payload SQLite stores in a cell, its
+// default SQLITE_MAX_LENGTH
+const sqliteMaxPayload = 1_000_000_000
+
+// sqliteDB reads the b-trees of an SQLite database file. It understands
+// just enough of the file format to count the rows of a table, so that no
+// SQLite library is needed. Changes still in the write-ahead log are not
+// seen.
+type sqliteDB struct {
+	r        io.ReaderAt
+	pageSize int
+	usable   int // Page size less the bytes reserved at the end of each page
+}
+
+// openSQLite checks the header of an SQLite database
+func openSQLite(r io.ReaderAt) (*sqliteDB, error) {
+	header := make([]byte, 100)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(header[:16], sqliteHeader) {
+		return nil, errors.New("not an SQLite 3 database")
+	}
+
+	// A page size of 1 stands for 65536, which does not fit in 16 bits
+	pageSize := int(binary.BigEndian.Uint16(header[16:18]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+	if pageSize < 512 || pageSize&(pageSize-1) != 0 {
+		return nil, fmt.Errorf("invalid page size %d", pageSize)
+	}
+	return &sqliteDB{r: r, pageSize: pageSize, usable: pageSize - int(header[20])}, nil
+}
+
+// page reads a page by its number, counted from 1
+func (db *sqliteDB) page(n uint32) ([]byte, error) {
+	if n == 0 {
+		return nil, errors.New("page 0 does not exist")
+	}
+	data := make([]byte, db.pageSize)
+	if _, err := db.r.ReadAt(data, int64(n-1)*int64(db.pageSize)); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// walkTable calls leaf with the payload of every cell of a table b-tree,
+// in rowid order; only the part of a payload stored on its page is given.
+// Each page is read at most once, so that a corrupt database whose pages
+// point back at each other cannot loop.
+func (db *sqliteDB) walkTable(root uint32, leaf func(payload []byte) error) error {
+	return db.walk(root, 0, make(map[uint32]bool), leaf)
+}
+
+func (db *sqliteDB) walk(n uint32, depth int, visited map[uint32]bool, leaf func(payload []byte) error) error {
+	if depth > sqliteMaxDepth {
+		return errors.New("b-tree too deep")
+	}
+	if visited[n] {
+		return fmt.Errorf("page %d is referenced twice", n)
+	}
+	visited[n] = true
+	data, err := db.page(n)
+	if err != nil {
+		return err
+	}
+	// The first page starts with the database header
+	header := 0
+	if n == 1 {
+		header = 100
+	}
+	if header+12 > len(data) {
+		return errors.New("truncated page")
+	}
+
+	cells := int(binary.BigEndian.Uint16(data[header+3:]))
+	switch data[header] {
+	case 0x05: // Interior table page: children to the left of each cell, then the right-most
+		pointers := header + 12
+		for i := 0; i < cells; i++ {
+			offset, err := cellOffset(data, pointers, i)
+			if err != nil || offset+4 > len(data) {
+				return errors.New("invalid cell pointer")
+			}
+			if err := db.walk(binary.BigEndian.Uint32(data[offset:]), depth+1, visited, leaf); err != nil {
+				return err
+			}
+		}
+		return db.walk(binary.BigEndian.Uint32(data[header+8:]), depth+1, visited, leaf)
+
+	case 0x0d: // Leaf table page: payload size, rowid, payload
+		pointers := header + 8
+		for i := 0; i < cells; i++ {
+			offset, err := cellOffset(data, pointers, i)
+			if err != nil {
+				return err
+			}
+			size, k := sqliteVarint(data[offset:])
+			_, l := sqliteVarint(data[offset+k:])
+			if k == 0 || l == 0 || size > sqliteMaxPayload {
+				return errors.New("invalid cell")
+			}
+			start := offset + k + l
+			end := start + db.localPayload(int(size))
+			if end > len(data) {
+				return errors.New("invalid cell")
+			}
+			if err := leaf(data[start:end]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("page %d is not a table b-tree page", n)
+}
+
+// cellOffset reads the i-th entry of a page's cell pointer array
+func cellOffset(data []byte, pointers, i int) (int, error) {
+	if pointers+2*i+2 > len(data) {
+		return 0, errors.New("invalid cell pointer")
+	}
+	offset := int(binary.BigEndian.Uint16(data[pointers+2*i:]))
+	if offset >= len(data) {
+		return 0, errors.New("invalid cell pointer")
+	}
+	return offset, nil
+}
+
+// localPayload is how much of a table leaf cell's payload is stored on the
+// page, the rest spilling to overflow pages
+func (db *sqliteDB) localPayload(size int) int {
+	maxLocal := db.usable - 35
+	if size <= maxLocal {
+		return size
+	}
+	minLocal := (db.usable-12)*32/255 - 23
+	local := minLocal + (size-minLocal)%(db.usable-4)
+	if local > maxLocal {
+		return minLocal
+	}
+	return local
+}
+
+// tableRoot finds the root page of a table in the schema table, which
+// always has its root on page 1
+func (db *sqliteDB) tableRoot(table string) (uint32, error) {
+	var root uint32
+	err := db.walkTable(1, func(payload []byte) error {
+		// Columns are type, name, tbl_name, rootpage and sql
+		columns, err := sqliteRecord(payload, 4)
+		if err != nil || root != 0 {
+			return nil
+		}
+		if kind, _ := columns[0].(string); kind != "table" {
+			return nil
+		}
+		if name, _ := columns[1].(string); name == table {
+			page, _ := columns[3].(int64)
+			root = uint32(page)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if root == 0 {
+		return 0, fmt.Errorf("no table %s", table)
+	}
+	return root, nil
+}
+
+// sqliteVarint decodes a big-endian SQLite varint, returning its length
+// or 0 if data ends first
+func sqliteVarint(data []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < 9 && i < len(data); i++ {
+		// The ninth byte contributes all eight bits
+		if i == 8 {
+			return v<<8 | uint64(data[i]), 9
+		}
+		v = v<<7 | uint64(data[i]&0x7f)
+		if data[i]&0x80 == 0 {
+			return v, i + 1
+		}
+	}
+	return 0, 0
+}
+
+// sqliteRecord decodes the first n columns of a record as int64, string,
+// []byte or nil. Floats are not needed and decode as nil.
+func sqliteRecord(payload []byte, n int) ([]any, error) {
+	headerSize, k := sqliteVarint(payload)
+	if k == 0 || headerSize > uint64(len(payload)) {
+		return nil, errors.New("invalid record header")
+	}
+
+	var columns []any
+	pos, body := k, int(headerSize)
+	for len(columns) < n && pos < int(headerSize) {
+		serial, l := sqliteVarint(payload[pos:int(headerSize)])
+		if l == 0 {
+			return nil, errors.New("invalid record header")
+		}
+		pos += l
+
+		var size uint64
+		switch {
+		case serial <= 4:
+			size = serial
+		case serial == 5:
+			size = 6
+		case serial == 6, serial == 7:
+			size = 8
+		case serial >= 12:
+			size = (serial - 12) / 2
+		}
+		if size > uint64(len(payload)-body) {
+			return nil, errors.New("record spills to an overflow page")
+		}
+		value := payload[body : body+int(size)]
+		body += int(size)
+
+		switch {
+		case serial >= 1 && serial <= 6:
+			// Big-endian two's complement integers
+			v := int64(int8(value[0]))
+			for _, b := range value[1:] {
+				v = v<<8 | int64(b)
+			}
+			columns = append(columns, v)
+		case serial == 8:
+			columns = append(columns, int64(0))
+		case serial == 9:
+			columns = append(columns, int64(1))
+		case serial >= 13 && serial%2 == 1:
+			columns = append(columns, string(value))
+		case serial >= 12:
+			columns = append(columns, value)
+		default:
+			columns = append(columns, nil)
+		}
+	}
+	if len(columns) < n {
+		return nil, errors.New("record has too few columns")
+	}
+	return columns, nil
+}
+
+// countSQLiteRows counts the rows of a table in an SQLite database file
+// below the host's root
+func countSQLiteRows(h *Host, path, table string) (int, error) {
+	f, err := os.Open(h.Path(path))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	db, err := openSQLite(f)
+	if err != nil {
+		return 0, err
+	}
+	root, err := db.tableRoot(table)
+	if err != nil {
+		return 0, err
+	}
+	rows := 0
+	err = db.walkTable(root, func([]byte) error {
+		rows++
+		return nil
+	})
+	return rows, err
+}
@@ -0,0 +1,64 @@
+package sysinfo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// corruptSQLite builds a database of 512-byte pages. Pages 1 to 30 are
+// interior pages whose cell and right-most pointer both lead to the next,
+// which a walk without a record of visited pages follows 2^30 times down to
+// the empty leaf on page 31. Page 32 is a leaf whose only cell claims a
+// payload larger than any int.
+func corruptSQLite() []byte {
+	const pages = 32
+	data := make([]byte, pages*512)
+	copy(data, sqliteHeader)
+	binary.BigEndian.PutUint16(data[16:], 512)
+
+	for n := 1; n < 31; n++ {
+		page := data[(n-1)*512 : n*512]
+		header := page
+		if n == 1 {
+			header = page[100:]
+		}
+		header[0] = 0x05
+		binary.BigEndian.PutUint16(header[3:], 1)
+		binary.BigEndian.PutUint32(header[8:], uint32(n+1))
+		binary.BigEndian.PutUint16(header[12:], 300)
+		binary.BigEndian.PutUint32(page[300:], uint32(n+1))
+		page[304] = 1
+	}
+
+	data[30*512] = 0x0d
+
+	leaf := data[31*512:]
+	leaf[0] = 0x0d
+	binary.BigEndian.PutUint16(leaf[3:], 1)
+	binary.BigEndian.PutUint16(leaf[8:], 100)
+	copy(leaf[100:], bytes.Repeat([]byte{0xff}, 9))
+	leaf[109] = 1
+	return data
+}
+
+func TestSQLiteRejectsCorruptPages(t *testing.T) {
+	db, err := openSQLite(bytes.NewReader(corruptSQLite()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := func([]byte) error { return nil }
+
+	if err := db.walkTable(1, count); err == nil {
+		t.Error("walkTable followed a page referenced twice")
+	}
+	if err := db.walkTable(32, count); err == nil {
+		t.Error("walkTable accepted a cell with an oversized payload")
+	}
+	if _, err := sqliteRecord([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, 1); err == nil {
+		t.Error("sqliteRecord accepted an oversized header")
+	}
+	if _, err := sqliteRecord([]byte{0x0a, 0x8f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f, 0}, 1); err == nil {
+		t.Error("sqliteRecord accepted an oversized column")
+	}
+}
@@ -0,0 +1,142 @@
+// Package sysinfotest loads captured machines for testing the collectors.
+//
+// A fixture is a directory holding a root/ tree of captured procfs, sysfs
+// and /etc files, and a host.json describing what cannot be captured as
+// files: the architecture, environment, command output, installed
+// executables, filesystem usage and the files the capturing user was not
+// allowed to read.
+package sysinfotest
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"testing"
+
+	"peekfetch/internal/sysinfo"
+
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+// Dir is the fixture corpus, relative to the sysinfo package
+const Dir = "testdata/fixtures"
+
+// manifest is the layout of host.json
+type manifest struct {
+	Arch     string                    `json:"arch"`
+	Hostname string                    `json:"hostname"`
+	Env      map[string]string         `json:"env"`
+	Commands map[string]string         `json:"commands"` // Output by command line
+	Paths    []string                  `json:"paths"`    // Executables on $PATH
+	Usage    map[string]disk.UsageStat `json:"usage"`    // Usage by mount point
+	Denied   []string                  `json:"denied"`   // Files only root could read
+}
+
+// Names lists the fixtures in dir
+func Names(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, e := range entries {
+		if e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
+
+// Load returns a Host that replays the fixture in dir
+func Load(dir string) (*sysinfo.Host, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "host.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%s: %w", dir, err)
+	}
+
+	root, err := filepath.Abs(filepath.Join(dir, "root"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &sysinfo.Host{
+		Root: root,
+		Arch: m.Arch,
+		Getenv: func(key string) string {
+			return m.Env[key]
+		},
+		Run: func(ctx context.Context, name string, args ...string) ([]byte, error) {
+			line := strings.Join(append([]string{name}, args...), " ")
+			if out, ok := m.Commands[line]; ok {
+				return []byte(out), nil
+			}
+			return nil, &exec.Error{Name: name, Err: exec.ErrNotFound}
+		},
+		LookPath: func(file string) (string, error) {
+			if slices.Contains(m.Paths, file) {
+				return "/usr/bin/" + file, nil
+			}
+			return "", &exec.Error{Name: file, Err: exec.ErrNotFound}
+		},
+		Hostname: func() (string, error) {
+			return m.Hostname, nil
+		},
+		Usage: func(ctx context.Context, path string) (*disk.UsageStat, error) {
+			mount := "/" + strings.TrimPrefix(strings.TrimPrefix(path, root), "/")
+			usage, ok := m.Usage[mount]
+			if !ok {
+				return nil, fmt.Errorf("no usage captured for %s", mount)
+			}
+			usage.Path = mount
+			return &usage, nil
+		},
+		Signal: func(pid int, sig syscall.Signal) error {
+			return fmt.Errorf("cannot signal process %d of a fixture", pid)
+		},
+		SetNice: func(pid, nice int) error {
+			return fmt.Errorf("cannot renice process %d of a fixture", pid)
+		},
+		Denied: func(path string) bool {
+			return slices.Contains(m.Denied, path)
+		},
+	}, nil
+}
+
+var update = flag.Bool("update", false, "rewrite golden files with the current output")
+
+// Golden compares got with the golden file at path, rewriting the file
+// instead when the test runs with -update
+func Golden(t testing.TB, path string, got []byte) {
+	t.Helper()
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("output differs from %s (run go test -update to accept it)\n--- got ---\n%s\n--- want ---\n%s", path, got, want)
+	}
+}
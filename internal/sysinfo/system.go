@@ -1,101 +1,147 @@
 package sysinfo
 
 import (
-	"bufio"
+	"context"
 	"fmt"
-	"os"
-	"os/exec"
-	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
 	"peekfetch/internal/types"
 
-	"github.com/shirou/gopsutil/v3/host"
 	"github.com/shirou/gopsutil/v3/load"
 )
 
 // GetSystemInfo collects detailed system information
-func GetSystemInfo() types.Section {
-	info := make(map[string]string)
+func GetSystemInfo(ctx context.Context) types.Section {
+	h := hostOf(ctx)
+	info := make(map[string]types.Field)
 	order := []string{}
 
 	// Hostname
-	hostname, _ := os.Hostname()
-	info["Hostname"] = hostname
+	hostname := getHostname(h)
+	info["Hostname"] = types.Text(hostname)
 	order = append(order, "Hostname")
 
+	// Machine model, from the firmware
+	if machine := getMachine(h); machine != "" {
+		info["Machine"] = types.Text(machine)
+		order = append(order, "Machine")
+	}
+
 	// User
-	if user := os.Getenv("USER"); user != "" {
-		info["User"] = user
+	if user := h.Getenv("USER"); user != "" {
+		info["User"] = types.Text(user)
 		order = append(order, "User")
 	}
 
-	// OS
-	hostInfo, _ := host.Info()
-	info["OS"] = fmt.Sprintf("%s %s", hostInfo.Platform, hostInfo.PlatformVersion)
+	// OS, with what os-release adds to its name
+	release, _ := ReadOSRelease(h)
+	info["OS"] = types.Text(release.String())
 	order = append(order, "OS")
+	if len(release.IDLike) > 0 {
+		info["Based On"] = types.Text(strings.Join(release.IDLike, ", "))
+		order = append(order, "Based On")
+	}
+	if codename := release.VersionCodename; codename != "" && !strings.Contains(strings.ToLower(release.String()), codename) {
+		info["Codename"] = types.Text(codename)
+		order = append(order, "Codename")
+	}
+	if release.BuildID != "" {
+		info["Build"] = types.Text(release.BuildID)
+		order = append(order, "Build")
+	}
 
 	// Kernel
-	info["Kernel"] = hostInfo.KernelVersion
-	order = append(order, "Kernel")
+	if data, err := h.ReadFile("/proc/sys/kernel/osrelease"); err == nil {
+		info["Kernel"] = types.Text(strings.TrimSpace(string(data)))
+		order = append(order, "Kernel")
+	}
 
 	// Architecture
-	info["Architecture"] = runtime.GOARCH
+	info["Architecture"] = types.Text(h.Arch)
 	order = append(order, "Architecture")
 
+	// Hypervisor and container runtime, reported separately since a
+	// container may itself run in a VM
+	virt := detectVM(h)
+	if virt == "" {
+		virt = "None (bare metal)"
+	}
+	info["Virtualization"] = types.Text(virt)
+	order = append(order, "Virtualization")
+	if container := detectContainer(h); container != "" {
+		info["Container"] = types.Text(container)
+		order = append(order, "Container")
+	}
+
 	// Uptime
-	uptime := time.Duration(hostInfo.Uptime) * time.Second
-	info["Uptime"] = formatDuration(uptime)
-	order = append(order, "Uptime")
+	if uptime, ok := getUptime(h); ok {
+		info["Uptime"] = types.Duration(uptime)
+		order = append(order, "Uptime")
+	}
 
 	// Boot time
-	bootTime := time.Unix(int64(hostInfo.BootTime), 0)
-	info["Boot Time"] = bootTime.Format("2006-01-02 15:04:05")
-	order = append(order, "Boot Time")
+	if bootTime, ok := getBootTime(h); ok {
+		info["Boot Time"] = types.Time(bootTime)
+		order = append(order, "Boot Time")
+	}
+
+	// Installed packages, by package manager
+	if packages := getPackages(h); packages != "" {
+		info["Packages"] = types.Text(packages)
+		order = append(order, "Packages")
+	}
 
 	// Shell
-	if shell := os.Getenv("SHELL"); shell != "" {
+	if shell := h.Getenv("SHELL"); shell != "" {
 		// Extract just the shell name
 		parts := strings.Split(shell, "/")
 		shellName := parts[len(parts)-1]
 
 		// Try to get version
-		if version := getShellVersion(shellName); version != "" {
-			info["Shell"] = fmt.Sprintf("%s %s", shellName, version)
+		if version := getShellVersion(ctx, h, shellName); version != "" {
+			info["Shell"] = types.Text(fmt.Sprintf("%s %s", shellName, version))
 		} else {
-			info["Shell"] = shellName
+			info["Shell"] = types.Text(shellName)
 		}
 		order = append(order, "Shell")
 	}
 
 	// Terminal
-	if term := os.Getenv("TERM"); term != "" {
-		info["Terminal"] = term
+	if term := h.Getenv("TERM"); term != "" {
+		info["Terminal"] = types.Text(term)
 		order = append(order, "Terminal")
 	}
 
 	// Desktop Environment / Window Manager
-	if de := getDesktopEnvironment(); de != "" {
-		info["Desktop"] = de
+	if de := getDesktopEnvironment(ctx, h); de != "" {
+		info["Desktop"] = types.Text(de)
 		order = append(order, "Desktop")
 	}
 
 	// Display info
-	if display := os.Getenv("DISPLAY"); display != "" {
-		info["Display"] = display
+	if display := h.Getenv("DISPLAY"); display != "" {
+		info["Display"] = types.Text(display)
 		order = append(order, "Display")
 	}
 
+	// Native resolution of each connected monitor, read from the kernel so
+	// that no X or Wayland connection is needed
+	if resolution := getResolutions(h); resolution != "" {
+		info["Resolution"] = types.Text(resolution)
+		order = append(order, "Resolution")
+	}
+
 	// Load average
-	if avg, err := load.Avg(); err == nil {
-		info["Load Average"] = fmt.Sprintf("%.2f, %.2f, %.2f", avg.Load1, avg.Load5, avg.Load15)
+	if avg, err := load.AvgWithContext(ctx); err == nil {
+		info["Load Average"] = types.Text(fmt.Sprintf("%.2f, %.2f, %.2f", avg.Load1, avg.Load5, avg.Load15))
 		order = append(order, "Load Average")
 	}
 
 	// Number of processes
-	if procs := countProcesses(); procs > 0 {
-		info["Processes"] = fmt.Sprintf("%d", procs)
+	if procs := countProcesses(h); procs > 0 {
+		info["Processes"] = types.Count(uint64(procs))
 		order = append(order, "Processes")
 	}
 
@@ -108,21 +154,54 @@ func GetSystemInfo() types.Section {
 	}
 }
 
-func getShellVersion(shell string) string {
-	var cmd *exec.Cmd
+// getUptime reads the time since boot from /proc/uptime
+func getUptime(h *Host) (time.Duration, bool) {
+	data, err := h.ReadFile("/proc/uptime")
+	if err != nil {
+		return 0, false
+	}
 
+	// Format is "uptime idle", both in seconds
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return 0, false
+	}
+	seconds, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, false
+	}
+	return time.Duration(seconds * float64(time.Second)), true
+}
+
+// getBootTime reads the btime line of /proc/stat
+func getBootTime(h *Host) (time.Time, bool) {
+	data, err := h.ReadFile("/proc/stat")
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "btime" {
+			seconds, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return time.Time{}, false
+			}
+			return time.Unix(seconds, 0), true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func getShellVersion(ctx context.Context, h *Host, shell string) string {
 	switch shell {
-	case "bash":
-		cmd = exec.Command("bash", "--version")
-	case "zsh":
-		cmd = exec.Command("zsh", "--version")
-	case "fish":
-		cmd = exec.Command("fish", "--version")
+	case "bash", "zsh", "fish":
 	default:
 		return ""
 	}
 
-	output, err := cmd.Output()
+	output, err := h.Run(ctx, shell, "--version")
 	if err != nil {
 		return ""
 	}
@@ -141,7 +220,7 @@ func getShellVersion(shell string) string {
 	return ""
 }
 
-func getDesktopEnvironment() string {
+func getDesktopEnvironment(ctx context.Context, h *Host) string {
 	// Try various environment variables
 	envVars := []string{
 		"XDG_CURRENT_DESKTOP",
@@ -150,7 +229,7 @@ func getDesktopEnvironment() string {
 	}
 
 	for _, envVar := range envVars {
-		if val := os.Getenv(envVar); val != "" {
+		if val := h.Getenv(envVar); val != "" {
 			return val
 		}
 	}
@@ -158,10 +237,9 @@ func getDesktopEnvironment() string {
 	// Try to detect window manager
 	wms := []string{"i3", "sway", "bspwm", "awesome", "dwm", "xmonad"}
 	for _, wm := range wms {
-		if _, err := exec.LookPath(wm); err == nil {
+		if _, err := h.LookPath(wm); err == nil {
 			// Check if it's running
-			cmd := exec.Command("pgrep", "-x", wm)
-			if err := cmd.Run(); err == nil {
+			if _, err := h.Run(ctx, "pgrep", "-x", wm); err == nil {
 				return wm
 			}
 		}
@@ -170,16 +248,28 @@ func getDesktopEnvironment() string {
 	return ""
 }
 
-func countProcesses() int {
-	file, err := os.Open("/proc/loadavg")
+// getHostname returns the hostname, read from /etc/hostname below an
+// alternate root since the container's own hostname would be wrong there
+func getHostname(h *Host) string {
+	if !h.IsLocal() {
+		if data, err := h.ReadFile("/etc/hostname"); err == nil {
+			if hostname := strings.TrimSpace(string(data)); hostname != "" {
+				return hostname
+			}
+		}
+	}
+	hostname, _ := h.Hostname()
+	return hostname
+}
+
+func countProcesses(h *Host) int {
+	data, err := h.ReadFile("/proc/loadavg")
 	if err != nil {
 		return 0
 	}
-	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	if scanner.Scan() {
-		fields := strings.Fields(scanner.Text())
+	if line, _, _ := strings.Cut(string(data), "\n"); line != "" {
+		fields := strings.Fields(line)
 		if len(fields) >= 4 {
 			// Format is "load1 load5 load15 running/total pid"
 			if strings.Contains(fields[3], "/") {
@@ -196,21 +286,10 @@ func countProcesses() int {
 	return 0
 }
 
-func formatDuration(d time.Duration) string {
-	days := int(d.Hours()) / 24
-	hours := int(d.Hours()) % 24
-	minutes := int(d.Minutes()) % 60
+type systemCollector struct{}
 
-	parts := []string{}
-	if days > 0 {
-		parts = append(parts, fmt.Sprintf("%dd", days))
-	}
-	if hours > 0 {
-		parts = append(parts, fmt.Sprintf("%dh", hours))
-	}
-	if minutes > 0 || len(parts) == 0 {
-		parts = append(parts, fmt.Sprintf("%dm", minutes))
-	}
+func (systemCollector) Name() string { return "System" }
 
-	return strings.Join(parts, " ")
+func (systemCollector) Collect(ctx context.Context) types.Section {
+	return GetSystemInfo(ctx)
 }
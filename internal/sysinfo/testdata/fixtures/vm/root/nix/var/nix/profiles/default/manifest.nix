@@ -0,0 +1 @@
+[ { meta = { description = "The purely functional package manager"; }; name = "nix-2.23.3"; out = { outPath = "/nix/store/7r1kq7dv4g2a1m8y7b8w0a1kkc0a2m8x-nix-2.23.3"; }; outPath = "/nix/store/7r1kq7dv4g2a1m8y7b8w0a1kkc0a2m8x-nix-2.23.3"; outputs = [ "out" ]; system = "x86_64-linux"; type = "derivation"; } { meta = { }; name = "nss-cacert-3.101"; out = { outPath = "/nix/store/4m9kc6xq3f0yrz2cz7h0c2dc2n2m8h3l-nss-cacert-3.101"; }; outPath = "/nix/store/4m9kc6xq3f0yrz2cz7h0c2dc2n2m8h3l-nss-cacert-3.101"; outputs = [ "out" ]; system = "x86_64-linux"; type = "derivation"; } ]
\ No newline at end of file
@@ -0,0 +1,164 @@
+package sysinfo
+
+import "strings"
+
+// dmiHypervisors maps substrings of DMI vendor and product strings to the
+// hypervisor that sets them, checked in order
+var dmiHypervisors = []struct {
+	marker, name string
+}{
+	{"KVM", "KVM"},
+	{"OpenStack", "KVM"},
+	{"KubeVirt", "KVM"},
+	{"Amazon EC2", "Amazon EC2"},
+	{"Google Compute Engine", "Google Compute Engine"},
+	{"QEMU", "QEMU"},
+	{"VMware", "VMware"},
+	{"VMW", "VMware"},
+	{"innotek GmbH", "Oracle VirtualBox"},
+	{"VirtualBox", "Oracle VirtualBox"},
+	{"Xen", "Xen"},
+	{"Bochs", "Bochs"},
+	{"Parallels", "Parallels"},
+	{"BHYVE", "bhyve"},
+	{"Apple Virtualization", "Apple Virtualization"},
+	{"Virtual Machine", "Microsoft Hyper-V"},
+}
+
+// dmiVirtAttrs are the DMI attributes a hypervisor names itself in
+var dmiVirtAttrs = []string{"sys_vendor", "product_name", "product_version", "board_vendor", "bios_vendor"}
+
+// cgroupRuntimes maps substrings of PID 1's cgroup path to the container
+// runtime that placed it there, checked in order
+var cgroupRuntimes = []struct {
+	marker, name string
+}{
+	{"kubepods", "Kubernetes"},
+	{"/docker", "Docker"},
+	{"/libpod", "Podman"},
+	{"/lxc", "LXC"},
+	{"/machine.slice/machine-", "systemd-nspawn"},
+}
+
+// containerNames are the spellings of $container, as set by container
+// managers for PID 1, that read better capitalized
+var containerNames = map[string]string{
+	"docker":         "Docker",
+	"podman":         "Podman",
+	"lxc":            "LXC",
+	"lxc-libvirt":    "LXC",
+	"systemd-nspawn": "systemd-nspawn",
+	"oci":            "OCI",
+	"wsl":            "WSL",
+}
+
+// detectVM returns the hypervisor the machine runs under, "" for bare
+// metal, in the spirit of systemd-detect-virt
+func detectVM(h *Host) string {
+	// Xen guests and hosts both have /proc/xen; only guests lack the
+	// control domain capability
+	if t, _ := h.ReadString("/sys/hypervisor/type"); t == "xen" {
+		if caps, _ := h.ReadString("/proc/xen/capabilities"); strings.Contains(caps, "control_d") {
+			return ""
+		}
+		return "Xen"
+	}
+
+	dmi := ""
+	for _, attr := range dmiVirtAttrs {
+		value, err := h.ReadString(dmiDir + attr)
+		if err != nil {
+			continue
+		}
+		for _, hv := range dmiHypervisors {
+			if strings.Contains(value, hv.marker) {
+				dmi = hv.name
+				break
+			}
+		}
+		if dmi != "" {
+			break
+		}
+	}
+
+	// QEMU with hardware acceleration is KVM, which shows in its clock
+	clocks, _ := h.ReadString("/sys/devices/system/clocksource/clocksource0/available_clocksource")
+	if strings.Contains(clocks, "kvm-clock") && (dmi == "" || dmi == "QEMU") {
+		return "KVM"
+	}
+	if dmi != "" {
+		return dmi
+	}
+	if strings.Contains(clocks, "hyperv_clocksource") {
+		return "Microsoft Hyper-V"
+	}
+
+	// Any other hypervisor still sets the CPU's hypervisor flag
+	if cpuinfo, err := h.ReadFile("/proc/cpuinfo"); err == nil {
+		for _, line := range strings.Split(string(cpuinfo), "\n") {
+			key, flags, ok := strings.Cut(line, ":")
+			if ok && strings.TrimSpace(key) == "flags" && strings.Contains(" "+flags+" ", " hypervisor ") {
+				return "Unknown hypervisor"
+			}
+		}
+	}
+	return ""
+}
+
+// detectContainer returns the container runtime peekfetch runs in, ""
+// outside of one
+func detectContainer(h *Host) string {
+	// WSL runs Linux in a lightweight VM but, like systemd, is reported
+	// as a container since the distribution shares it with others
+	if version, _ := h.ReadString("/proc/version"); strings.Contains(version, "Microsoft") || strings.Contains(version, "WSL") {
+		return "WSL"
+	}
+	if release, _ := h.ReadString("/proc/sys/kernel/osrelease"); strings.Contains(release, "Microsoft") || strings.Contains(release, "WSL") {
+		return "WSL"
+	}
+
+	// OpenVZ containers see /proc/vz but not the host's /proc/bc
+	if h.Exists("/proc/vz") && !h.Exists("/proc/bc") {
+		return "OpenVZ"
+	}
+
+	// Set by the container manager for systemd, or in PID 1's environment
+	if name, _ := h.ReadString("/run/systemd/container"); name != "" {
+		return containerName(name)
+	}
+	if environ, err := h.ReadFile("/proc/1/environ"); err == nil {
+		for _, v := range strings.Split(string(environ), "\x00") {
+			if name, ok := strings.CutPrefix(v, "container="); ok && name != "" {
+				return containerName(name)
+			}
+		}
+	}
+
+	if h.Exists("/run/.containerenv") {
+		return "Podman"
+	}
+	if h.Exists("/.dockerenv") {
+		return "Docker"
+	}
+	if h.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		return "Kubernetes"
+	}
+
+	// Without a cgroup namespace PID 1 shows where the runtime put it
+	if cgroup, err := h.ReadFile("/proc/1/cgroup"); err == nil {
+		for _, rt := range cgroupRuntimes {
+			if strings.Contains(string(cgroup), rt.marker) {
+				return rt.name
+			}
+		}
+	}
+	return ""
+}
+
+// containerName spells a $container value for display
+func containerName(name string) string {
+	if display, ok := containerNames[name]; ok {
+		return display
+	}
+	return name
+}
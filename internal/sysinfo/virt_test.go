@@ -0,0 +1,68 @@
+package sysinfo
+
+import (
+	"testing"
+)
+
+func TestDetectVM(t *testing.T) {
+	host, _ := tempHost(t, map[string]string{"proc/cpuinfo": "processor\t: 0\nflags\t\t: fpu vme de pse\n"})
+	if vm := detectVM(host); vm != "" {
+		t.Errorf("detectVM = %q on bare metal, want none", vm)
+	}
+
+	// Only the CPU tells
+	writeFiles(t, host, map[string]string{"proc/cpuinfo": "processor\t: 0\nflags\t\t: fpu vme hypervisor de\n"})
+	if vm := detectVM(host); vm != "Unknown hypervisor" {
+		t.Errorf("detectVM = %q, want an unknown hypervisor", vm)
+	}
+
+	// QEMU accelerated by KVM
+	writeFiles(t, host, map[string]string{"sys/class/dmi/id/sys_vendor": "QEMU\n"})
+	if vm := detectVM(host); vm != "QEMU" {
+		t.Errorf("detectVM = %q, want QEMU", vm)
+	}
+	writeFiles(t, host, map[string]string{"sys/devices/system/clocksource/clocksource0/available_clocksource": "kvm-clock tsc acpi_pm \n"})
+	if vm := detectVM(host); vm != "KVM" {
+		t.Errorf("detectVM = %q, want KVM", vm)
+	}
+
+	// A cloud's own DMI name is kept
+	writeFiles(t, host, map[string]string{"sys/class/dmi/id/sys_vendor": "Amazon EC2\n"})
+	if vm := detectVM(host); vm != "Amazon EC2" {
+		t.Errorf("detectVM = %q, want Amazon EC2", vm)
+	}
+
+	// Xen's control domain runs the hypervisor rather than under it
+	writeFiles(t, host, map[string]string{
+		"sys/hypervisor/type":   "xen\n",
+		"proc/xen/capabilities": "control_d\n",
+	})
+	if vm := detectVM(host); vm != "" {
+		t.Errorf("detectVM = %q in dom0, want none", vm)
+	}
+}
+
+func TestDetectContainer(t *testing.T) {
+	host, _ := tempHost(t, nil)
+	host.Getenv = func(string) string { return "" }
+
+	writeFiles(t, host, map[string]string{"proc/1/cgroup": "0::/init.scope\n"})
+	if c := detectContainer(host); c != "" {
+		t.Errorf("detectContainer = %q on the host, want none", c)
+	}
+
+	writeFiles(t, host, map[string]string{"proc/1/cgroup": "12:pids:/docker/3f4e1b\n0::/docker/3f4e1b\n"})
+	if c := detectContainer(host); c != "Docker" {
+		t.Errorf("detectContainer = %q, want Docker from the cgroup", c)
+	}
+
+	writeFiles(t, host, map[string]string{"proc/1/environ": "PATH=/usr/bin\x00container=lxc\x00"})
+	if c := detectContainer(host); c != "LXC" {
+		t.Errorf("detectContainer = %q, want LXC from $container", c)
+	}
+
+	writeFiles(t, host, map[string]string{"proc/version": "Linux version 5.15.153.1-microsoft-standard-WSL2 (root@941d701f84f1)\n"})
+	if c := detectContainer(host); c != "WSL" {
+		t.Errorf("detectContainer = %q, want WSL", c)
+	}
+}
@@ -0,0 +1,289 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Kind describes how a Field's value is interpreted and formatted
+type Kind int
+
+const (
+	KindText        Kind = iota // Free-form text
+	KindNumber                  // Plain number, such as a count
+	KindBytes                   // Size in bytes
+	KindPercent                 // Percentage between 0 and 100
+	KindDuration                // Duration in seconds
+	KindTemperature             // Temperature in degrees Celsius
+	KindFrequency               // Frequency in hertz
+	KindTime                    // Point in time as Unix seconds
+	KindByteRate                // Throughput in bytes per second
+	KindRate                    // Events per second, such as IOPS
+	KindLatency                 // Short duration in milliseconds
+	KindFanSpeed                // Fan speed in revolutions per minute
+	KindVoltage                 // Electric potential in volts
+	KindPower                   // Power draw in watts
+	KindEnergy                  // Stored energy in watt-hours
+	KindLength                  // Physical length in millimetres
+	KindDiagonal                // Screen diagonal in inches
+	KindCPUs                    // CPUs' worth of time, such as a CPU quota
+	KindRefreshRate             // Display refresh rate in hertz
+)
+
+// Units used by Field
+const (
+	UnitBytes    = "bytes"
+	UnitPercent  = "percent"
+	UnitSeconds  = "seconds"
+	UnitCelsius  = "celsius"
+	UnitHertz    = "hertz"
+	UnitCount    = "count"
+	UnitUnix     = "unix_seconds"
+	UnitByteRate = "bytes_per_second"
+	UnitRate     = "per_second"
+	UnitMillis   = "milliseconds"
+	UnitRPM      = "rpm"
+	UnitVolts    = "volts"
+	UnitWatts    = "watts"
+	UnitWattHrs  = "watt_hours"
+	UnitMM       = "millimetres"
+	UnitInches   = "inches"
+	UnitCPUs     = "cpus"
+)
+
+// Field is a single typed value within a section
+type Field struct {
+	Kind  Kind
+	Value float64 // Raw number, unused for KindText
+	Text  string  // Text value for KindText
+	Unit  string  // Unit of Value, one of the Unit* constants
+	Max   float64 // Optional upper bound, such as the total for a used count
+}
+
+// Text creates a text field
+func Text(s string) Field {
+	return Field{Kind: KindText, Text: s}
+}
+
+// Count creates a plain counting field
+func Count(n uint64) Field {
+	return Field{Kind: KindNumber, Value: float64(n), Unit: UnitCount}
+}
+
+// Number creates a plain numeric field with the given unit
+func Number(n float64, unit string) Field {
+	return Field{Kind: KindNumber, Value: n, Unit: unit}
+}
+
+// Bytes creates a size field
+func Bytes(n uint64) Field {
+	return Field{Kind: KindBytes, Value: float64(n), Unit: UnitBytes}
+}
+
+// Percent creates a percentage field
+func Percent(p float64) Field {
+	return Field{Kind: KindPercent, Value: p, Unit: UnitPercent, Max: 100}
+}
+
+// Duration creates a duration field with second precision
+func Duration(d time.Duration) Field {
+	return Field{Kind: KindDuration, Value: math.Floor(d.Seconds()), Unit: UnitSeconds}
+}
+
+// Temperature creates a temperature field in degrees Celsius
+func Temperature(celsius float64) Field {
+	return Field{Kind: KindTemperature, Value: celsius, Unit: UnitCelsius}
+}
+
+// Frequency creates a frequency field in hertz
+func Frequency(hz float64) Field {
+	return Field{Kind: KindFrequency, Value: hz, Unit: UnitHertz}
+}
+
+// RefreshRate creates a display refresh rate field in hertz
+func RefreshRate(hz float64) Field {
+	return Field{Kind: KindRefreshRate, Value: hz, Unit: UnitHertz}
+}
+
+// Time creates a point-in-time field
+func Time(t time.Time) Field {
+	return Field{Kind: KindTime, Value: float64(t.Unix()), Unit: UnitUnix}
+}
+
+// ByteRate creates a throughput field in bytes per second
+func ByteRate(bps float64) Field {
+	return Field{Kind: KindByteRate, Value: bps, Unit: UnitByteRate}
+}
+
+// Rate creates a field counting events per second
+func Rate(n float64) Field {
+	return Field{Kind: KindRate, Value: n, Unit: UnitRate}
+}
+
+// Latency creates a field for a short duration, kept in milliseconds
+func Latency(d time.Duration) Field {
+	return Field{Kind: KindLatency, Value: float64(d) / float64(time.Millisecond), Unit: UnitMillis}
+}
+
+// FanSpeed creates a fan speed field in revolutions per minute
+func FanSpeed(rpm float64) Field {
+	return Field{Kind: KindFanSpeed, Value: rpm, Unit: UnitRPM}
+}
+
+// Voltage creates a voltage field in volts
+func Voltage(volts float64) Field {
+	return Field{Kind: KindVoltage, Value: volts, Unit: UnitVolts}
+}
+
+// Power creates a power field in watts
+func Power(watts float64) Field {
+	return Field{Kind: KindPower, Value: watts, Unit: UnitWatts}
+}
+
+// Energy creates a stored energy field in watt-hours
+func Energy(wh float64) Field {
+	return Field{Kind: KindEnergy, Value: wh, Unit: UnitWattHrs}
+}
+
+// Length creates a physical length field in millimetres
+func Length(mm float64) Field {
+	return Field{Kind: KindLength, Value: mm, Unit: UnitMM}
+}
+
+// Diagonal creates a screen diagonal field in inches
+func Diagonal(inches float64) Field {
+	return Field{Kind: KindDiagonal, Value: inches, Unit: UnitInches}
+}
+
+// CPUs creates a field counting CPUs' worth of time, which may be
+// fractional
+func CPUs(n float64) Field {
+	return Field{Kind: KindCPUs, Value: n, Unit: UnitCPUs}
+}
+
+// WithMax returns a copy of the field with an upper bound set
+func (f Field) WithMax(max float64) Field {
+	f.Max = max
+	return f
+}
+
+// IsNumeric reports whether the field carries a raw number
+func (f Field) IsNumeric() bool {
+	return f.Kind != KindText
+}
+
+// IsIntegral reports whether the raw number is naturally a whole number,
+// such as a byte count or a number of seconds
+func (f Field) IsIntegral() bool {
+	switch f.Kind {
+	case KindBytes, KindDuration, KindTime:
+		return true
+	case KindNumber:
+		return f.Value == math.Trunc(f.Value)
+	}
+	return false
+}
+
+// Ratio returns how full the field is as a percentage, for fields that
+// have an upper bound
+func (f Field) Ratio() (float64, bool) {
+	if f.Max <= 0 {
+		return 0, false
+	}
+	return f.Value / f.Max * 100, true
+}
+
+// String formats the field for display
+func (f Field) String() string {
+	switch f.Kind {
+	case KindNumber:
+		if f.Max > 0 {
+			return fmt.Sprintf("%s / %s", formatNumber(f.Value), formatNumber(f.Max))
+		}
+		return formatNumber(f.Value)
+	case KindBytes:
+		return formatBytes(uint64(f.Value))
+	case KindPercent:
+		return fmt.Sprintf("%.1f%%", f.Value)
+	case KindDuration:
+		return formatDuration(time.Duration(f.Value) * time.Second)
+	case KindTemperature:
+		if f.Max > 0 {
+			return fmt.Sprintf("%.1f°C / %.1f°C", f.Value, f.Max)
+		}
+		return fmt.Sprintf("%.1f°C", f.Value)
+	case KindFrequency:
+		return fmt.Sprintf("%.2f GHz", f.Value/1e9)
+	case KindTime:
+		return time.Unix(int64(f.Value), 0).Format("2006-01-02 15:04:05")
+	case KindByteRate:
+		return formatBytes(uint64(max(0, f.Value))) + "/s"
+	case KindRate:
+		return fmt.Sprintf("%.1f/s", f.Value)
+	case KindLatency:
+		return fmt.Sprintf("%.2f ms", f.Value)
+	case KindFanSpeed:
+		return fmt.Sprintf("%.0f RPM", f.Value)
+	case KindVoltage:
+		return fmt.Sprintf("%.2f V", f.Value)
+	case KindPower:
+		return fmt.Sprintf("%.2f W", f.Value)
+	case KindEnergy:
+		return fmt.Sprintf("%.2f Wh", f.Value)
+	case KindLength:
+		return fmt.Sprintf("%.0f mm", f.Value)
+	case KindRefreshRate:
+		return fmt.Sprintf("%.2f Hz", f.Value)
+	case KindCPUs:
+		if f.Max > 0 {
+			return fmt.Sprintf("%.2f / %s CPUs", f.Value, formatNumber(f.Max))
+		}
+		return fmt.Sprintf("%.2f CPUs", f.Value)
+	case KindDiagonal:
+		return fmt.Sprintf("%.1f\"", f.Value)
+	}
+	return f.Text
+}
+
+func formatNumber(n float64) string {
+	if n == math.Trunc(n) {
+		return fmt.Sprintf("%d", int64(n))
+	}
+	return fmt.Sprintf("%.2f", n)
+}
+
+func formatBytes(bytes uint64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	div, exp := uint64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.2f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+func formatDuration(d time.Duration) string {
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+
+	parts := []string{}
+	if days > 0 {
+		parts = append(parts, fmt.Sprintf("%dd", days))
+	}
+	if hours > 0 {
+		parts = append(parts, fmt.Sprintf("%dh", hours))
+	}
+	if minutes > 0 || len(parts) == 0 {
+		parts = append(parts, fmt.Sprintf("%dm", minutes))
+	}
+
+	return strings.Join(parts, " ")
+}
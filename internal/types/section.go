@@ -4,16 +4,18 @@ package types
 type Section struct {
 	Name     string
 	Expanded bool
-	Data     map[string]string
+	Data     map[string]Field
 	TreeData []TreeItem // Hierarchical data structure
 	LiveData bool       // Whether this section supports live updates
 	Order    []string   // Order of keys for display
 	UseTree  bool       // Whether to use tree structure for display
+	Grids    [][]string // Groups of bounded keys drawn as a compact grid of bars
+	Table    *Table     // Rows for table sections such as Processes
 }
 
 // TreeItem represents a hierarchical data item
 type TreeItem struct {
 	Name     string
-	Children map[string]string
+	Children map[string]Field
 	Order    []string
 }
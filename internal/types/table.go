@@ -0,0 +1,22 @@
+package types
+
+// Table is a list of rows with typed columns, such as the process list
+type Table struct {
+	Columns   []Column
+	Rows      []Row
+	Processes bool // Rows are processes identified by PID, which can be signalled or reniced
+}
+
+// Column describes one table column
+type Column struct {
+	Name  string
+	Width int  // Display width; zero takes the remaining width
+	Desc  bool // Sort descending first, as for usage columns
+}
+
+// Row is one table row, such as a process
+type Row struct {
+	ID     string  // Stable identity across refreshes, such as the PID
+	Parent string  // ID of the parent row, such as the parent PID, for tables drawn as a tree
+	Cells  []Field // One per column
+}
@@ -0,0 +1,183 @@
+package ui
+
+import (
+	"strings"
+
+	"peekfetch/internal/types"
+)
+
+// DefaultHistorySize is how many live samples are kept per field
+const DefaultHistorySize = 30
+
+// sparkWidth is the width of a sparkline in cells
+const sparkWidth = 15
+
+// sparkLevels are the block elements of a sparkline, lowest first
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+// Ring keeps the most recent samples of one live field
+type Ring struct {
+	samples []float64
+	start   int
+	count   int
+}
+
+// NewRing creates a ring holding up to size samples
+func NewRing(size int) *Ring {
+	if size < 1 {
+		size = 1
+	}
+	return &Ring{samples: make([]float64, size)}
+}
+
+// Push adds a sample, dropping the oldest one when the ring is full
+func (r *Ring) Push(v float64) {
+	if r.count < len(r.samples) {
+		r.samples[(r.start+r.count)%len(r.samples)] = v
+		r.count++
+		return
+	}
+	r.samples[r.start] = v
+	r.start = (r.start + 1) % len(r.samples)
+}
+
+// Len returns the number of samples held
+func (r *Ring) Len() int {
+	return r.count
+}
+
+// Values returns the samples, oldest first
+func (r *Ring) Values() []float64 {
+	values := make([]float64, r.count)
+	for i := range values {
+		values[i] = r.samples[(r.start+i)%len(r.samples)]
+	}
+	return values
+}
+
+// Stats returns the minimum, average and maximum of the samples
+func (r *Ring) Stats() (lo, avg, hi float64) {
+	values := r.Values()
+	if len(values) == 0 {
+		return 0, 0, 0
+	}
+
+	lo, hi = values[0], values[0]
+	sum := 0.0
+	for _, v := range values {
+		lo = min(lo, v)
+		hi = max(hi, v)
+		sum += v
+	}
+	return lo, sum / float64(len(values)), hi
+}
+
+// historyKey identifies a field across refreshes. Item is empty for flat
+// sections.
+func historyKey(section, item, field string) string {
+	return section + "\x00" + item + "\x00" + field
+}
+
+// record pushes every numeric field of a refreshed section into its ring
+func (m Model) record(section types.Section) {
+	if m.History == nil {
+		return
+	}
+
+	push := func(item string, data map[string]types.Field) {
+		for key, value := range data {
+			if !value.IsNumeric() {
+				continue
+			}
+			k := historyKey(section.Name, item, key)
+			ring, ok := m.History[k]
+			if !ok {
+				ring = NewRing(m.HistorySize)
+				m.History[k] = ring
+			}
+			ring.Push(value.Value)
+		}
+	}
+
+	push("", section.Data)
+	for _, item := range section.TreeData {
+		push(item.Name, item.Children)
+	}
+}
+
+// renderHistory renders a sparkline of a field's samples followed by their
+// minimum, average and maximum, or "" until there are two samples
+func (m Model) renderHistory(section, item, key string, value types.Field) string {
+	ring, ok := m.History[historyKey(section, item, key)]
+	if !ok || ring.Len() < 2 {
+		return ""
+	}
+
+	// Bounded fields are scaled to their bound so that the sparkline
+	// matches the progress bar; others to the range of the window
+	lo, avg, hi := ring.Stats()
+	floor, ceil := lo, hi
+	if value.Max > 0 {
+		floor, ceil = 0, value.Max
+	}
+
+	stat := func(v float64) string {
+		f := value
+		f.Value = v
+		f.Max = 0
+		return f.String()
+	}
+
+	return SparklineStyle.Render(sparkline(ring.Values(), sparkWidth, floor, ceil)) + " " +
+		TreeStyle.Render("min ") + ValueStyle.Render(stat(lo)) +
+		TreeStyle.Render(" avg ") + ValueStyle.Render(stat(avg)) +
+		TreeStyle.Render(" max ") + ValueStyle.Render(stat(hi))
+}
+
+// rateWidth is the widest formatted byte rate, such as "1023.99 KiB/s",
+// so that the sparklines after rates of different sizes line up
+const rateWidth = 13
+
+// renderRateHistory renders the history of an unbounded field when it is
+// a throughput, padded to follow its value, or "" for any other field
+func (m Model) renderRateHistory(section, item, key string, value types.Field) string {
+	if value.Kind != types.KindByteRate {
+		return ""
+	}
+	history := m.renderHistory(section, item, key, value)
+	if history == "" {
+		return ""
+	}
+	padding := max(0, rateWidth-len(value.String()))
+	return strings.Repeat(" ", padding) + "  " + history
+}
+
+// sparkline draws values scaled between lo and hi in at most width cells.
+// When there are more values than cells, each cell shows the average of a
+// run of consecutive values.
+func sparkline(values []float64, width int, lo, hi float64) string {
+	if len(values) > width {
+		buckets := make([]float64, width)
+		for i := range buckets {
+			from := i * len(values) / width
+			to := (i + 1) * len(values) / width
+			sum := 0.0
+			for _, v := range values[from:to] {
+				sum += v
+			}
+			buckets[i] = sum / float64(to-from)
+		}
+		values = buckets
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		level := 0
+		if hi > lo {
+			level = int((v - lo) / (hi - lo) * float64(len(sparkLevels)-1))
+		}
+		level = max(0, min(level, len(sparkLevels)-1))
+		b.WriteRune(sparkLevels[level])
+	}
+	return b.String()
+}
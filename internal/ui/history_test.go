@@ -0,0 +1,39 @@
+package ui
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRingKeepsNewestSamples(t *testing.T) {
+	r := NewRing(3)
+	for _, v := range []float64{1, 2, 3, 4, 5} {
+		r.Push(v)
+	}
+
+	if got, want := r.Values(), []float64{3, 4, 5}; !slices.Equal(got, want) {
+		t.Errorf("Values() = %v, want %v", got, want)
+	}
+	if lo, avg, hi := r.Stats(); lo != 3 || avg != 4 || hi != 5 {
+		t.Errorf("Stats() = %v, %v, %v, want 3, 4, 5", lo, avg, hi)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		values []float64
+		width  int
+		lo, hi float64
+		want   string
+	}{
+		{[]float64{0, 50, 100}, 10, 0, 100, "▁▄█"},
+		{[]float64{0, 0, 100, 100}, 2, 0, 100, "▁█"},
+		{[]float64{7, 7}, 10, 7, 7, "▁▁"},
+	}
+
+	for _, tt := range tests {
+		if got := sparkline(tt.values, tt.width, tt.lo, tt.hi); got != tt.want {
+			t.Errorf("sparkline(%v, %d, %v, %v) = %q, want %q", tt.values, tt.width, tt.lo, tt.hi, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"peekfetch/internal/sysinfo"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// wordmark is the PeekFetch name shown beside the distribution's logo
+const wordmark = `   ___           _   ___     _       _
+  / _ \___ ___ | | _| __|__| |_ ___| |__
+ | (_) / -_) -_)| |/ / _|/ -_)  _/ _| '_ \
+  \___/\___\___||___/|___\___\__\__|_| |_|`
+
+// bannerLines is the height the header reserves for the wordmark alone;
+// taller logos take lines from the viewport
+const bannerLines = 4
+
+// distroLogo is the ASCII logo of a distribution and the os-release IDs
+// it is shown for
+type distroLogo struct {
+	ids []string
+	art string
+}
+
+// distroLogos are matched against a system's ID, then each of its ID_LIKE
+// in turn, so that derivatives without a logo of their own show their
+// parent's
+var distroLogos = []distroLogo{
+	{[]string{"alpine"}, `
+   /\ /\
+  // \  \
+ //   \  \
+///    \  \
+//      \  \
+         \`},
+	{[]string{"arch", "archarm", "archlinux"}, `
+      /\
+     /  \
+    /\   \
+   /      \
+  /   ,,   \
+ /   |  |  -\
+/_-''    ''-_\`},
+	{[]string{"centos"}, `
+ ____^____
+ |\  |  /|
+ | \ | / |
+<---- ---->
+ | / | \ |
+ |/__|__\|
+     v`},
+	{[]string{"debian"}, `
+  _____
+ /  __ \
+|  /    |
+|  \___-
+-_
+  --_`},
+	{[]string{"fedora"}, `
+      _____
+     /   __)\
+     |  /  \ \
+  ___|  |__/ /
+ / (_    _)_/
+/ /  |  |
+\ \__/  |
+ \(_____/`},
+	{[]string{"gentoo"}, `
+ _-----_
+(       \
+\    0   \
+ \        )
+ /      _/
+(     _-
+\____-`},
+	{[]string{"linuxmint"}, `
+ ___________
+|_          \
+  | | _____ |
+  | | | | | |
+  | | | | | |
+  | \_____/ |
+  \_________/`},
+	{[]string{"manjaro"}, `
+||||||||| ||||
+||||||||| ||||
+||||      ||||
+|||| |||| ||||
+|||| |||| ||||
+|||| |||| ||||
+|||| |||| ||||`},
+	{[]string{"nixos"}, `
+  \\  \\ //
+ ==\\__\\/ //
+   //   \\//
+==//     //==
+ //\\___//
+// /\\  \\==
+  // \\  \\`},
+	{[]string{"opensuse", "opensuse-leap", "opensuse-tumbleweed", "suse", "sles"}, `
+  _______
+__|   __ \
+     / .\ \
+     \__/ |
+   _______|
+   \_______
+__________/`},
+	{[]string{"pop"}, `
+______
+\   _ \        __
+ \ \ \ \      / /
+  \ \_\ \    / /
+   \  ___\  /_/
+    \ \    _
+   __\_\__(_)_
+  (___________)`},
+	{[]string{"raspbian"}, `
+   ..    ,.
+  :oo: .:oo:
+  'o\o o/o:
+ :: . :: . ::
+:: :::  ::: ::
+:  :: :: ::  :
+ ::: .:: :::
+  '::::::'`},
+	{[]string{"rhel", "redhat"}, `
+    .M.:MMM
+   MMMMMMMMMM.
+  ,MMMMMMMMMMM
+ .MM MMMMMMMMMMMMMMMM
+ MMM.   MMMMMMMMMMMMM
+  'MMMMMMMMMMMMMMM'
+      'MMMMMMMM'`},
+	{[]string{"ubuntu"}, `
+         _
+     ---(_)
+ _/  ---  \
+(_) |   |
+  \  --- _/
+     ---(_)`},
+	{[]string{"void"}, `
+    _______
+ _ \______ -
+| \  ___  \ |
+| | /   \ | |
+| | \___/ | |
+| \______ \_|
+ -_______\`},
+}
+
+// tuxLogo is shown for distributions without a logo
+const tuxLogo = `
+    ___
+   (.. |
+   (<> |
+  / __  \
+ ( /  \ /|
+_/\ __)/_)
+\/-____\/`
+
+// logoFor finds the logo of a distribution by its ID, then by the IDs it
+// is like
+func logoFor(release sysinfo.OSRelease) string {
+	for _, id := range append([]string{release.ID}, release.IDLike...) {
+		for _, logo := range distroLogos {
+			if slices.Contains(logo.ids, id) {
+				return strings.TrimPrefix(logo.art, "\n")
+			}
+		}
+	}
+	return strings.TrimPrefix(tuxLogo, "\n")
+}
+
+// ansiColor converts the SGR parameters of ANSI_COLOR, such as "0;31",
+// "1;34", "38;5;33" or "38;2;23;147;209", to a terminal color
+func ansiColor(sgr string) (lipgloss.TerminalColor, bool) {
+	var params []int
+	for _, p := range strings.Split(sgr, ";") {
+		n, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil {
+			return nil, false
+		}
+		params = append(params, n)
+	}
+
+	var color lipgloss.TerminalColor
+	bold := false
+	for i := 0; i < len(params); i++ {
+		switch n := params[i]; {
+		case n == 1:
+			bold = true
+		case n >= 30 && n <= 37:
+			color = lipgloss.ANSIColor(n - 30)
+		case n >= 90 && n <= 97:
+			color = lipgloss.ANSIColor(n - 90 + 8)
+		case n == 38 && i+2 < len(params) && params[i+1] == 5:
+			color = lipgloss.ANSIColor(params[i+2])
+			i += 2
+		case n == 38 && i+4 < len(params) && params[i+1] == 2:
+			color = lipgloss.Color(fmt.Sprintf("#%02x%02x%02x", params[i+2], params[i+3], params[i+4]))
+			i += 4
+		}
+	}
+	// Bold makes the eight basic colors bright in most terminals
+	if c, ok := color.(lipgloss.ANSIColor); ok && bold && c < 8 {
+		color = c + 8
+	}
+	return color, color != nil
+}
+
+// renderBanner draws the distribution's logo beside the wordmark and its
+// name. The logo takes the distribution's ANSI_COLOR, unless the theme
+// keeps to the terminal's default colors.
+func renderBanner(release sysinfo.OSRelease) string {
+	logoStyle := BannerStyle.Align(lipgloss.Left)
+	if _, plain := ColorPrimary.(lipgloss.NoColor); !plain {
+		if color, ok := ansiColor(release.ANSIColor); ok {
+			logoStyle = logoStyle.Foreground(color)
+		}
+	}
+
+	name := lipgloss.JoinVertical(lipgloss.Center,
+		BannerStyle.Align(lipgloss.Left).Render(wordmark), "", BannerStyle.Render(release.String()))
+	return lipgloss.JoinHorizontal(lipgloss.Center, logoStyle.Render(logoFor(release)), "   ", name)
+}
@@ -0,0 +1,56 @@
+package ui
+
+import (
+	"testing"
+
+	"peekfetch/internal/sysinfo"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestLogoFor(t *testing.T) {
+	tests := []struct {
+		release sysinfo.OSRelease
+		want    []string // IDs of the expected logo, nil for Tux
+	}{
+		{sysinfo.OSRelease{ID: "arch"}, []string{"arch"}},
+		// A derivative without a logo shows its closest parent's
+		{sysinfo.OSRelease{ID: "rocky", IDLike: []string{"rhel", "centos", "fedora"}}, []string{"rhel"}},
+		{sysinfo.OSRelease{ID: "endeavouros", IDLike: []string{"arch"}}, []string{"arch"}},
+		{sysinfo.OSRelease{ID: "slackware"}, nil},
+	}
+
+	for _, tt := range tests {
+		want := tuxLogo
+		for _, logo := range distroLogos {
+			if tt.want != nil && logo.ids[0] == tt.want[0] {
+				want = logo.art
+			}
+		}
+		if got := logoFor(tt.release); "\n"+got != want {
+			t.Errorf("logoFor(%s) =\n%s", tt.release.ID, got)
+		}
+	}
+}
+
+func TestANSIColor(t *testing.T) {
+	tests := []struct {
+		sgr  string
+		want lipgloss.TerminalColor
+	}{
+		{"0;31", lipgloss.ANSIColor(1)},
+		{"1;34", lipgloss.ANSIColor(12)},
+		{"0;94", lipgloss.ANSIColor(12)},
+		{"38;5;33", lipgloss.ANSIColor(33)},
+		{"38;2;23;147;209", lipgloss.Color("#1793d1")},
+		{"1", nil},
+		{"red", nil},
+	}
+
+	for _, tt := range tests {
+		got, ok := ansiColor(tt.sgr)
+		if ok != (tt.want != nil) || got != tt.want {
+			t.Errorf("ansiColor(%q) = %v, %v, want %v", tt.sgr, got, ok, tt.want)
+		}
+	}
+}
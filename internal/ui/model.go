@@ -1,16 +1,32 @@
 package ui
 
 import (
+	"context"
 	"time"
 
 	"peekfetch/internal/sysinfo"
 	"peekfetch/internal/types"
 
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 type Model struct {
 	Sections       []types.Section
+	Loading        []bool // Whether each section is still being collected, by index
+	Registry       *sysinfo.Registry
+	Release        sysinfo.OSRelease     // Distribution whose logo heads the view
+	Collectors     []sysinfo.Collector   // Collector for each section, by index
+	LastRefresh    []time.Time           // Last live refresh of each section, by index
+	Refreshing     []bool                // Whether a live refresh is in flight, by index
+	TickInterval   time.Duration         // Shortest live refresh interval
+	History        map[string]*Ring      // Recent samples of every live numeric field
+	HistorySize    int                   // Samples kept per field
+	Tables         map[string]*tableView // Cursor, sort and filter of table sections, by name
+	Prompt         *processAction        // Process action awaiting confirmation
+	Detail         *types.Section        // Contents of the open process detail pane
+	Status         string                // Outcome of the last process action
+	Spinner        spinner.Model
 	SelectedIndex  int
 	ScrollOffset   int // Scroll offset for expanded content
 	LiveMode       bool
@@ -19,28 +35,91 @@ type Model struct {
 	ViewportHeight int // Available height for content
 }
 
+// Options are the user preferences that shape the initial model
+type Options struct {
+	LiveMode    bool                   // Start in live mode
+	Expanded    func(name string) bool // Whether a section starts expanded
+	HistorySize int                    // Live samples kept per field; zero keeps the default
+}
+
 type tickMsg time.Time
 
-// TickCmd returns a command that sends a tick message every 500ms
-func tickCmd() tea.Cmd {
-	return tea.Tick(500*time.Millisecond, func(t time.Time) tea.Msg {
+// sectionMsg delivers a collected section to the model
+type sectionMsg struct {
+	index   int
+	section types.Section
+}
+
+// refreshMsg delivers a live-refreshed section to the model
+type refreshMsg struct {
+	index   int
+	section types.Section
+}
+
+// tickCmd returns a command that sends a tick message after interval
+func tickCmd(interval time.Duration) tea.Cmd {
+	return tea.Tick(interval, func(t time.Time) tea.Msg {
 		return tickMsg(t)
 	})
 }
 
-// InitialModel creates the initial model with all sections
-func InitialModel() Model {
+// collectCmd returns a command that collects one section in the background
+func collectCmd(registry *sysinfo.Registry, c sysinfo.Collector, index int) tea.Cmd {
+	return func() tea.Msg {
+		return sectionMsg{index: index, section: registry.Collect(context.Background(), c)}
+	}
+}
+
+// refreshCmd returns a command that refreshes one live section in the
+// background, so sampling never blocks Update
+func refreshCmd(registry *sysinfo.Registry, c sysinfo.LiveCollector, index int, section types.Section) tea.Cmd {
+	return func() tea.Msg {
+		return refreshMsg{index: index, section: registry.Refresh(context.Background(), c, section)}
+	}
+}
+
+// InitialModel creates the initial model with a loading placeholder per
+// registered collector. Sections are filled in as collection finishes.
+func InitialModel(registry *sysinfo.Registry, opts Options) Model {
+	collectors := registry.Collectors()
+
+	var tickInterval time.Duration
+	sections := make([]types.Section, len(collectors))
+	loading := make([]bool, len(collectors))
+	for i, c := range collectors {
+		sections[i] = types.Section{Name: c.Name()}
+		if opts.Expanded != nil {
+			sections[i].Expanded = opts.Expanded(c.Name())
+		}
+		loading[i] = true
+		if live, ok := c.(sysinfo.LiveCollector); ok && (tickInterval == 0 || live.Interval() < tickInterval) {
+			tickInterval = live.Interval()
+		}
+	}
+	if tickInterval == 0 {
+		tickInterval = sysinfo.DefaultInterval
+	}
+	historySize := opts.HistorySize
+	if historySize <= 0 {
+		historySize = DefaultHistorySize
+	}
+
 	return Model{
-		Sections: []types.Section{
-			sysinfo.GetSystemInfo(),
-			sysinfo.GetCPUInfo(),
-			sysinfo.GetMemoryInfo(),
-			sysinfo.GetDiskInfo(),
-			sysinfo.GetNetworkInfo(),
-		},
+		Sections:       sections,
+		Loading:        loading,
+		Registry:       registry,
+		Release:        registry.OSRelease(),
+		Collectors:     collectors,
+		LastRefresh:    make([]time.Time, len(collectors)),
+		Refreshing:     make([]bool, len(collectors)),
+		TickInterval:   tickInterval,
+		History:        make(map[string]*Ring),
+		HistorySize:    historySize,
+		Tables:         make(map[string]*tableView),
+		Spinner:        spinner.New(spinner.WithSpinner(spinner.Dot), spinner.WithStyle(SpinnerStyle)),
 		SelectedIndex:  0,
 		ScrollOffset:   0,
-		LiveMode:       false,
+		LiveMode:       opts.LiveMode,
 		Width:          80,
 		Height:         24,
 		ViewportHeight: 20,
@@ -48,5 +127,22 @@ func InitialModel() Model {
 }
 
 func (m Model) Init() tea.Cmd {
-	return nil
+	cmds := []tea.Cmd{m.Spinner.Tick}
+	if m.LiveMode {
+		cmds = append(cmds, tickCmd(m.TickInterval))
+	}
+	for i, c := range m.Collectors {
+		cmds = append(cmds, collectCmd(m.Registry, c, i))
+	}
+	return tea.Batch(cmds...)
+}
+
+// isLoading reports whether any section is still being collected
+func (m Model) isLoading() bool {
+	for _, loading := range m.Loading {
+		if loading {
+			return true
+		}
+	}
+	return false
 }
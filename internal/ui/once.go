@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"peekfetch/internal/sysinfo"
+	"peekfetch/internal/types"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// UsePlainText disables colors and text attributes for all styles, for
+// output that is not going to a terminal
+func UsePlainText() {
+	lipgloss.SetColorProfile(termenv.Ascii)
+}
+
+// RenderOnce renders every section fully expanded as a static summary,
+// headed by the distribution's logo and using the same key/value and tree
+// layout as the interactive view
+func RenderOnce(sections []types.Section, release sysinfo.OSRelease) string {
+	var b strings.Builder
+	m := Model{Sections: sections, Release: release}
+
+	b.WriteString("\n" + renderBanner(release) + "\n")
+	b.WriteString("\n")
+
+	for _, section := range sections {
+		icon := getSectionIcon(section.Name)
+		b.WriteString(SectionHeaderStyle.Render(fmt.Sprintf("%s %s", icon, section.Name)))
+		b.WriteString("\n")
+
+		var lines []string
+		if section.Table != nil {
+			// Only the busiest rows, by the table's default sort
+			header, rows := m.renderTable(section, false)
+			lines = append(header, rows[:min(len(rows), onceTableRows)]...)
+			if more := len(rows) - onceTableRows; more > 0 {
+				lines = append(lines, TreeStyle.Render(fmt.Sprintf("… %d more", more)))
+			}
+		} else {
+			lines = m.renderSectionContent(section)
+		}
+		content := strings.Join(lines, "\n")
+		b.WriteString(ExpandedContentStyle.Render(content))
+		b.WriteString("\n")
+	}
+
+	// Lipgloss pads every line to the block width; drop that padding so
+	// the summary stays clean when pasted into logs
+	lines := strings.Split(b.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
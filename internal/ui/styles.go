@@ -4,101 +4,163 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
-// Color palette - Calm, modern, professional colors
+// Color palette of the active theme, set by ApplyTheme
 var (
-	ColorPrimary   = lipgloss.Color("#7DCFFF") // Soft blue
-	ColorSecondary = lipgloss.Color("#BB9AF7") // Soft purple
-	ColorSuccess   = lipgloss.Color("#9ECE6A") // Soft green
-	ColorWarning   = lipgloss.Color("#E0AF68") // Soft amber
-	ColorDanger    = lipgloss.Color("#F7768E") // Soft red
-	ColorInfo      = lipgloss.Color("#7AA2F7") // Medium blue
-	ColorAccent    = lipgloss.Color("#73DACA") // Soft teal
-	ColorMuted     = lipgloss.Color("#565F89") // Muted blue-gray
-	ColorText      = lipgloss.Color("#C0CAF5") // Light periwinkle
-	ColorSubtle    = lipgloss.Color("#9AA5CE") // Subtle text
-	ColorBg        = lipgloss.Color("#1A1B26") // Deep blue-black
-	ColorBorder    = lipgloss.Color("#414868") // Border gray
+	ColorPrimary   lipgloss.TerminalColor
+	ColorSecondary lipgloss.TerminalColor
+	ColorSuccess   lipgloss.TerminalColor
+	ColorWarning   lipgloss.TerminalColor
+	ColorDanger    lipgloss.TerminalColor
+	ColorInfo      lipgloss.TerminalColor
+	ColorAccent    lipgloss.TerminalColor
+	ColorMuted     lipgloss.TerminalColor
+	ColorText      lipgloss.TerminalColor
+	ColorSubtle    lipgloss.TerminalColor
+	ColorBg        lipgloss.TerminalColor
+	ColorBorder    lipgloss.TerminalColor
+	ColorHighlight lipgloss.TerminalColor
 )
 
-// Styles for the UI
+// Styles for the UI, built from the active theme by ApplyTheme
 var (
+	TitleStyle           lipgloss.Style
+	BannerStyle          lipgloss.Style
+	HeaderStyle          lipgloss.Style
+	SelectedStyle        lipgloss.Style
+	NormalStyle          lipgloss.Style
+	KeyStyle             lipgloss.Style
+	ValueStyle           lipgloss.Style
+	TreeStyle            lipgloss.Style
+	SubKeyStyle          lipgloss.Style
+	FooterStyle          lipgloss.Style
+	LiveBadgeStyle       lipgloss.Style
+	SectionHeaderStyle   lipgloss.Style
+	ExpandedContentStyle lipgloss.Style
+	ProgressBarStyle     lipgloss.Style
+	ProgressEmptyStyle   lipgloss.Style
+	SpinnerStyle         lipgloss.Style
+	SparklineStyle       lipgloss.Style
+	TableHeaderStyle     lipgloss.Style
+	RowSelectedStyle     lipgloss.Style
+	StatusStyle          lipgloss.Style
+)
+
+func init() {
+	ApplyTheme(DarkTheme)
+}
+
+// ApplyTheme switches the palette and rebuilds every style from it
+func ApplyTheme(t Theme) {
+	ColorPrimary = t.Primary
+	ColorSecondary = t.Secondary
+	ColorSuccess = t.Success
+	ColorWarning = t.Warning
+	ColorDanger = t.Danger
+	ColorInfo = t.Info
+	ColorAccent = t.Accent
+	ColorMuted = t.Muted
+	ColorText = t.Text
+	ColorSubtle = t.Subtle
+	ColorBg = t.Background
+	ColorBorder = t.Border
+	ColorHighlight = t.Highlight
+
 	TitleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(ColorPrimary).
-			PaddingLeft(1).
-			PaddingRight(1)
+		Bold(true).
+		Foreground(ColorPrimary).
+		PaddingLeft(1).
+		PaddingRight(1)
 
 	BannerStyle = lipgloss.NewStyle().
-			Foreground(ColorPrimary).
-			Bold(true).
-			Align(lipgloss.Center)
+		Foreground(ColorPrimary).
+		Bold(true).
+		Align(lipgloss.Center)
 
 	HeaderStyle = lipgloss.NewStyle().
-			Bold(true).
-			BorderStyle(lipgloss.DoubleBorder()).
-			BorderForeground(ColorPrimary).
-			Padding(0, 2).
-			MarginBottom(1)
+		Bold(true).
+		BorderStyle(lipgloss.DoubleBorder()).
+		BorderForeground(ColorPrimary).
+		Padding(0, 2).
+		MarginBottom(1)
 
 	SelectedStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(ColorSecondary).
-			Background(lipgloss.Color("#292E42")).
-			PaddingLeft(1).
-			PaddingRight(1).
-			BorderStyle(lipgloss.RoundedBorder()).
-			BorderLeft(true).
-			BorderForeground(ColorSecondary)
+		Bold(true).
+		Foreground(ColorSecondary).
+		Background(ColorHighlight).
+		PaddingLeft(1).
+		PaddingRight(1).
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderLeft(true).
+		BorderForeground(ColorSecondary)
 
 	NormalStyle = lipgloss.NewStyle().
-			Foreground(ColorText).
-			PaddingLeft(1)
+		Foreground(ColorText).
+		PaddingLeft(1)
 
 	KeyStyle = lipgloss.NewStyle().
-			Foreground(ColorAccent).
-			Bold(true)
+		Foreground(ColorAccent).
+		Bold(true)
 
 	ValueStyle = lipgloss.NewStyle().
-			Foreground(ColorSuccess)
+		Foreground(ColorSuccess)
 
 	TreeStyle = lipgloss.NewStyle().
-			Foreground(ColorBorder)
+		Foreground(ColorBorder)
 
 	SubKeyStyle = lipgloss.NewStyle().
-			Foreground(ColorInfo)
+		Foreground(ColorInfo)
 
 	FooterStyle = lipgloss.NewStyle().
-			Foreground(ColorMuted).
-			BorderStyle(lipgloss.RoundedBorder()).
-			BorderTop(true).
-			BorderForeground(ColorPrimary).
-			Padding(0, 2).
-			MarginTop(1).
-			Align(lipgloss.Center)
+		Foreground(ColorMuted).
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderTop(true).
+		BorderForeground(ColorPrimary).
+		Padding(0, 2).
+		MarginTop(1).
+		Align(lipgloss.Center)
 
 	LiveBadgeStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(ColorBg).
-			Background(ColorSuccess).
-			Padding(0, 1).
-			MarginLeft(1)
+		Bold(true).
+		Foreground(ColorBg).
+		Background(ColorSuccess).
+		Padding(0, 1).
+		MarginLeft(1)
 
 	SectionHeaderStyle = lipgloss.NewStyle().
-				Foreground(ColorPrimary).
-				Bold(true)
+		Foreground(ColorPrimary).
+		Bold(true)
 
 	ExpandedContentStyle = lipgloss.NewStyle().
-				MarginLeft(2).
-				BorderStyle(lipgloss.RoundedBorder()).
-				BorderLeft(true).
-				BorderForeground(ColorBorder).
-				PaddingLeft(2).
-				MarginTop(1).
-				MarginBottom(1)
+		MarginLeft(2).
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderLeft(true).
+		BorderForeground(ColorBorder).
+		PaddingLeft(2).
+		MarginTop(1).
+		MarginBottom(1)
 
 	ProgressBarStyle = lipgloss.NewStyle().
-				Foreground(ColorSuccess)
+		Foreground(ColorSuccess)
 
 	ProgressEmptyStyle = lipgloss.NewStyle().
-				Foreground(ColorMuted)
-)
+		Foreground(ColorMuted)
+
+	SpinnerStyle = lipgloss.NewStyle().
+		Foreground(ColorSecondary)
+
+	SparklineStyle = lipgloss.NewStyle().
+		Foreground(ColorInfo)
+
+	TableHeaderStyle = lipgloss.NewStyle().
+		Foreground(ColorInfo).
+		Bold(true)
+
+	RowSelectedStyle = lipgloss.NewStyle().
+		Foreground(ColorSecondary).
+		Background(ColorHighlight).
+		Bold(true)
+
+	StatusStyle = lipgloss.NewStyle().
+		Foreground(ColorWarning).
+		PaddingLeft(2)
+}
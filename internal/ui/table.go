@@ -0,0 +1,636 @@
+package ui
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"peekfetch/internal/sysinfo"
+	"peekfetch/internal/types"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// onceTableRows is how many rows of a table the one-shot summary shows
+const onceTableRows = 10
+
+// tableView is the interactive state of one table section
+type tableView struct {
+	cursor    string // ID of the selected row
+	index     int    // Position of the selected row, used once it disappears
+	sortCol   int
+	desc      bool
+	filter    string
+	filtering bool            // Whether keys are being typed into the filter
+	tree      bool            // Whether rows are drawn as a tree of parents and children
+	collapsed map[string]bool // Tree rows whose children are hidden, by ID
+	detail    string          // ID of the row whose detail pane is open
+	detailTop int             // Scroll offset of the detail pane
+}
+
+// newTableView sorts by the first column that sorts descending, such as
+// CPU% for processes, and by the first column otherwise
+func newTableView(table *types.Table) *tableView {
+	for i, col := range table.Columns {
+		if col.Desc {
+			return &tableView{sortCol: i, desc: true}
+		}
+	}
+	return &tableView{}
+}
+
+// tableOf returns the state of a table section, creating it on first use
+func (m Model) tableOf(section types.Section) *tableView {
+	if tv, ok := m.Tables[section.Name]; ok {
+		return tv
+	}
+	tv := newTableView(section.Table)
+	if m.Tables != nil {
+		m.Tables[section.Name] = tv
+	}
+	return tv
+}
+
+// rows returns the rows matching the filter, in display order
+func (tv *tableView) rows(table *types.Table) []types.Row {
+	rows, _ := tv.layout(table)
+	return rows
+}
+
+// layout returns the rows matching the filter in sort order. Ties keep the
+// order of the first column, the PID for processes. In tree mode children
+// follow their parent and the branch to draw before each row is returned
+// too; a filtered tree keeps the ancestors of every match.
+func (tv *tableView) layout(table *types.Table) ([]types.Row, []string) {
+	filter := strings.ToLower(tv.filter)
+	keep := make(map[string]bool)
+	for _, row := range table.Rows {
+		if filter == "" || rowMatches(row, filter) {
+			keep[row.ID] = true
+		}
+	}
+	if tv.tree && filter != "" {
+		parents := make(map[string]string, len(table.Rows))
+		for _, row := range table.Rows {
+			parents[row.ID] = row.Parent
+		}
+		for id := range maps.Clone(keep) {
+			for p, ok := parents[id]; ok && !keep[p]; p, ok = parents[p] {
+				keep[p] = true
+			}
+		}
+	}
+
+	rows := make([]types.Row, 0, len(keep))
+	for _, row := range table.Rows {
+		if keep[row.ID] {
+			rows = append(rows, row)
+		}
+	}
+
+	col := tv.sortCol
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i].Cells, rows[j].Cells
+		if col < len(a) && col < len(b) {
+			if c := compareCells(a[col], b[col]); c != 0 {
+				if tv.desc {
+					return c > 0
+				}
+				return c < 0
+			}
+		}
+		return len(a) > 0 && len(b) > 0 && compareCells(a[0], b[0]) < 0
+	})
+
+	if !tv.tree {
+		return rows, nil
+	}
+	return tv.treeLayout(rows)
+}
+
+// treeLayout orders sorted rows depth first under their parents, keeping
+// the sort order among siblings. Rows whose parent is not listed are roots.
+func (tv *tableView) treeLayout(rows []types.Row) ([]types.Row, []string) {
+	listed := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		listed[row.ID] = true
+	}
+	children := make(map[string][]types.Row)
+	var roots []types.Row
+	for _, row := range rows {
+		if row.Parent != row.ID && listed[row.Parent] {
+			children[row.Parent] = append(children[row.Parent], row)
+		} else {
+			roots = append(roots, row)
+		}
+	}
+
+	out := make([]types.Row, 0, len(rows))
+	branches := make([]string, 0, len(rows))
+	visited := make(map[string]bool, len(rows))
+
+	// parents holds whether each enclosing row below the root was the
+	// last of its siblings, as for treeBranch
+	var walk func(row types.Row, parents []bool, last, root bool)
+	walk = func(row types.Row, parents []bool, last, root bool) {
+		if visited[row.ID] {
+			return
+		}
+		visited[row.ID] = true
+
+		branch := ""
+		if !root {
+			branch = treeBranch(parents, last)
+			parents = append(slices.Clone(parents), last)
+		}
+		kids := children[row.ID]
+		if len(kids) > 0 && tv.collapsed[row.ID] {
+			branch += "▸"
+			kids = nil
+		}
+		out = append(out, row)
+		branches = append(branches, branch)
+
+		for i, kid := range kids {
+			walk(kid, parents, i == len(kids)-1, false)
+		}
+	}
+	for _, row := range roots {
+		walk(row, nil, true, true)
+	}
+	return out, branches
+}
+
+// selected returns the position of the selected row in rows, following
+// the row when it moves and staying in place when it disappears
+func (tv *tableView) selected(rows []types.Row) int {
+	for i, row := range rows {
+		if row.ID == tv.cursor {
+			return i
+		}
+	}
+	return max(0, min(tv.index, len(rows)-1))
+}
+
+// rowMatches reports whether any cell contains filter, which is lower case
+func rowMatches(row types.Row, filter string) bool {
+	for _, cell := range row.Cells {
+		if strings.Contains(strings.ToLower(cell.String()), filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// compareCells orders numeric cells by value and text cells by text
+func compareCells(a, b types.Field) int {
+	if a.IsNumeric() && b.IsNumeric() {
+		return cmp.Compare(a.Value, b.Value)
+	}
+	return strings.Compare(strings.ToLower(a.String()), strings.ToLower(b.String()))
+}
+
+// tableWindow returns the first row to show so that cursor is within the
+// visible rows, starting from offset
+func tableWindow(offset, cursor, visible int) int {
+	visible = max(1, visible)
+	if cursor < offset {
+		offset = cursor
+	}
+	if cursor >= offset+visible {
+		offset = cursor - visible + 1
+	}
+	return max(0, offset)
+}
+
+// tableFocused reports whether the selected section is an expanded table,
+// which then takes the navigation keys
+func (m Model) tableFocused() bool {
+	if len(m.Sections) == 0 || m.Loading[m.SelectedIndex] {
+		return false
+	}
+	section := m.Sections[m.SelectedIndex]
+	return section.Table != nil && section.Expanded
+}
+
+// processAction is a signal or renice waiting for confirmation
+type processAction struct {
+	index   int // Section the process is listed in
+	pid     int
+	command string
+	signal  syscall.Signal
+	name    string // Signal name, empty to renice
+	delta   int
+}
+
+// prompt asks for confirmation of the action
+func (a processAction) prompt() string {
+	if a.name != "" {
+		return fmt.Sprintf("Send %s to %d (%s)? [y/N]", a.name, a.pid, a.command)
+	}
+	return fmt.Sprintf("Renice %d (%s) by %+d? [y/N]", a.pid, a.command, a.delta)
+}
+
+// actionMsg reports the outcome of a process action
+type actionMsg struct {
+	index  int
+	status string
+}
+
+// actionCmd returns a command that runs a confirmed process action
+func actionCmd(registry *sysinfo.Registry, a processAction) tea.Cmd {
+	return func() tea.Msg {
+		if a.name != "" {
+			if err := registry.SignalProcess(a.pid, a.signal); err != nil {
+				return actionMsg{index: a.index, status: fmt.Sprintf("Error: %v", err)}
+			}
+			return actionMsg{index: a.index, status: fmt.Sprintf("Sent %s to %d", a.name, a.pid)}
+		}
+
+		nice, err := registry.ReniceProcess(a.pid, a.delta)
+		if err != nil {
+			return actionMsg{index: a.index, status: fmt.Sprintf("Error: %v", err)}
+		}
+		return actionMsg{index: a.index, status: fmt.Sprintf("Set nice of %d to %d", a.pid, nice)}
+	}
+}
+
+// updateTable handles a key while a table is focused and reports whether
+// it was used
+func (m Model) updateTable(msg tea.KeyMsg) (Model, tea.Cmd, bool) {
+	section := m.Sections[m.SelectedIndex]
+	tv := m.tableOf(section)
+	rows := tv.rows(section.Table)
+	cursor := tv.selected(rows)
+	visible := m.visibleRows(tv)
+
+	move := func(i int) {
+		if len(rows) == 0 {
+			return
+		}
+		i = max(0, min(i, len(rows)-1))
+		tv.index, tv.cursor = i, rows[i].ID
+		m.ScrollOffset = tableWindow(m.ScrollOffset, i, visible)
+	}
+
+	// Moving past the first or last row moves to the neighbouring section
+	nav := func() (Model, tea.Cmd, bool) {
+		target, ok := tableNav(msg.String(), cursor, len(rows), visible)
+		switch {
+		case !ok:
+			return m, nil, false
+		case target < 0 && m.SelectedIndex > 0:
+			m.SelectedIndex--
+			m.ScrollOffset = 0
+		case target >= len(rows) && m.SelectedIndex < len(m.Sections)-1:
+			m.SelectedIndex++
+			m.ScrollOffset = 0
+		default:
+			move(target)
+		}
+		return m, nil, true
+	}
+
+	if tv.filtering {
+		switch msg.Type {
+		case tea.KeyEnter:
+			tv.filtering = false
+			return m, nil, true
+		case tea.KeyEsc:
+			tv.filtering, tv.filter = false, ""
+		case tea.KeyBackspace:
+			if r := []rune(tv.filter); len(r) > 0 {
+				tv.filter = string(r[:len(r)-1])
+			}
+		case tea.KeySpace:
+			tv.filter += " "
+		case tea.KeyRunes:
+			tv.filter += string(msg.Runes)
+		default:
+			// Arrows and Ctrl+C keep working while typing
+			return nav()
+		}
+		rows = tv.rows(section.Table)
+		m.ScrollOffset = 0
+		move(tv.selected(rows))
+		return m, nil, true
+	}
+
+	// The detail pane scrolls with the navigation keys; actions still
+	// apply to the process it shows
+	if tv.detail != "" {
+		visible := m.detailHeight(tv)
+		last := max(0, len(m.detailLines())-visible)
+		switch msg.String() {
+		case "enter", "esc":
+			tv.detail, tv.detailTop, m.Detail = "", 0, nil
+		case "up", "k":
+			tv.detailTop = max(0, tv.detailTop-1)
+		case "down", "j":
+			tv.detailTop = min(last, tv.detailTop+1)
+		case "pageup", "ctrl+u":
+			tv.detailTop = max(0, tv.detailTop-visible/2)
+		case "pagedown", "ctrl+d":
+			tv.detailTop = min(last, tv.detailTop+visible/2)
+		case "t", "K", "+", "=", "-":
+			return m.tableAction(msg.String(), section, rows, cursor)
+		default:
+			return m, nil, false
+		}
+		return m, nil, true
+	}
+
+	switch msg.String() {
+	case "/":
+		tv.filtering = true
+	case "enter":
+		if !section.Table.Processes || len(rows) == 0 {
+			return m, nil, false
+		}
+		tv.detail, tv.detailTop, m.Detail = rows[cursor].ID, 0, nil
+		return m, detailCmd(m.Registry, section.Name, tv.detail), true
+	case "T":
+		tv.tree = !tv.tree
+		rows = tv.rows(section.Table)
+		move(tv.selected(rows))
+	case "left", "right":
+		if !tv.tree || len(rows) == 0 {
+			return m, nil, true
+		}
+		if tv.collapsed == nil {
+			tv.collapsed = make(map[string]bool)
+		}
+		if msg.String() == "left" {
+			tv.collapsed[rows[cursor].ID] = true
+		} else {
+			delete(tv.collapsed, rows[cursor].ID)
+		}
+		rows = tv.rows(section.Table)
+		move(tv.selected(rows))
+	case "esc":
+		if tv.filter == "" {
+			return m, nil, false
+		}
+		tv.filter = ""
+		rows = tv.rows(section.Table)
+		move(tv.selected(rows))
+	case "s":
+		tv.sortCol = (tv.sortCol + 1) % len(section.Table.Columns)
+		tv.desc = section.Table.Columns[tv.sortCol].Desc
+		rows = tv.rows(section.Table)
+		move(tv.selected(rows))
+	case "S":
+		tv.desc = !tv.desc
+		rows = tv.rows(section.Table)
+		move(tv.selected(rows))
+	case "t", "K", "+", "=", "-":
+		return m.tableAction(msg.String(), section, rows, cursor)
+	default:
+		return nav()
+	}
+	return m, nil, true
+}
+
+// tableAction asks to confirm a signal or renice of the selected process
+func (m Model) tableAction(key string, section types.Section, rows []types.Row, cursor int) (Model, tea.Cmd, bool) {
+	if !section.Table.Processes || len(rows) == 0 {
+		return m, nil, true
+	}
+	if !m.Registry.ControlsProcesses() {
+		m.Status = fmt.Sprintf("Error: %v", sysinfo.ErrNotLocal)
+		return m, nil, true
+	}
+	row := rows[cursor]
+	pid, err := strconv.Atoi(row.ID)
+	if err != nil {
+		return m, nil, true
+	}
+
+	action := processAction{index: m.SelectedIndex, pid: pid, command: row.Cells[len(row.Cells)-1].String()}
+	switch key {
+	case "t":
+		action.signal, action.name = syscall.SIGTERM, "SIGTERM"
+	case "K":
+		action.signal, action.name = syscall.SIGKILL, "SIGKILL"
+	case "-":
+		action.delta = -1
+	default:
+		action.delta = 1
+	}
+	m.Prompt = &action
+	return m, nil, true
+}
+
+// tableNav returns the row a navigation key moves the cursor to, which is
+// -1 or len(rows) when it moves past the first or last row
+func tableNav(key string, cursor, rows, visible int) (int, bool) {
+	switch key {
+	case "up", "k":
+		return cursor - 1, true
+	case "down", "j":
+		return cursor + 1, true
+	case "pageup", "ctrl+u":
+		return max(0, cursor-visible/2), true
+	case "pagedown", "ctrl+d":
+		return min(rows-1, cursor+visible/2), true
+	case "home":
+		return 0, true
+	case "end":
+		return rows - 1, true
+	}
+	return cursor, false
+}
+
+// visibleRows is how many rows fit below the pinned table header
+func (m Model) visibleRows(tv *tableView) int {
+	pinned := 1
+	if tv.filter != "" || tv.filtering {
+		pinned++
+	}
+	return max(1, m.ViewportHeight-pinned)
+}
+
+// detailMsg delivers the details of the process a detail pane shows
+type detailMsg struct {
+	table   string // Name of the table section
+	id      string
+	section types.Section
+	err     error
+}
+
+// detailCmd returns a command that reads the details of a process row in
+// the background
+func detailCmd(registry *sysinfo.Registry, table, id string) tea.Cmd {
+	return func() tea.Msg {
+		pid, err := strconv.Atoi(id)
+		if err != nil {
+			return detailMsg{table: table, id: id, err: err}
+		}
+		section, err := registry.ProcessDetails(pid)
+		return detailMsg{table: table, id: id, section: section, err: err}
+	}
+}
+
+// refreshDetail reloads an open detail pane after its table refreshed, so
+// that counters stay live
+func (m Model) refreshDetail(index int) tea.Cmd {
+	section := m.Sections[index]
+	if section.Table == nil {
+		return nil
+	}
+	tv, ok := m.Tables[section.Name]
+	if !ok || tv.detail == "" {
+		return nil
+	}
+	return detailCmd(m.Registry, section.Name, tv.detail)
+}
+
+// detailLines renders the detail pane using the tree layout of sections,
+// clipped to the terminal width since command lines can be long
+func (m Model) detailLines() []string {
+	if m.Detail == nil {
+		return []string{TreeStyle.Render("Loading…")}
+	}
+
+	clip := lipgloss.NewStyle().MaxWidth(m.contentWidth())
+
+	lines := append([]string{KeyStyle.Render(m.Detail.Name)}, m.renderSectionContent(*m.Detail)...)
+	for i, line := range lines {
+		lines[i] = clip.Render(line)
+	}
+	return lines
+}
+
+// detailHeight is how many detail lines fit below the table header and
+// the pinned selected row
+func (m Model) detailHeight(tv *tableView) int {
+	return max(1, m.visibleRows(tv)-1)
+}
+
+// renderTable renders the pinned header lines of a table section and one
+// line per row. The cursor row is highlighted when selected is set.
+func (m Model) renderTable(section types.Section, selected bool) (header, lines []string) {
+	table := section.Table
+	tv := m.tableOf(section)
+	rows, branches := tv.layout(table)
+
+	if tv.filter != "" || tv.filtering {
+		filter := KeyStyle.Render("Filter: ") + ValueStyle.Render(tv.filter)
+		if tv.filtering {
+			filter += ValueStyle.Render("▏")
+		}
+		filter += TreeStyle.Render(fmt.Sprintf(" (%d of %d)", len(rows), len(table.Rows)))
+		header = append(header, filter)
+	}
+
+	widths := m.columnWidths(table)
+	names := make([]string, len(table.Columns))
+	for i, col := range table.Columns {
+		name := col.Name
+		if i == tv.sortCol {
+			if tv.desc {
+				name += "▼"
+			} else {
+				name += "▲"
+			}
+		}
+		names[i] = pad(name, widths[i], rightAligned(table, i))
+	}
+	header = append(header, TableHeaderStyle.Render(strings.Join(names, " ")))
+
+	cursor := tv.selected(rows)
+	for r, row := range rows {
+		cells := make([]string, len(table.Columns))
+		for i := range table.Columns {
+			text := ""
+			if i < len(row.Cells) {
+				text = row.Cells[i].String()
+			}
+			cells[i] = pad(text, widths[i], rightAligned(table, i))
+		}
+
+		// In tree mode the last column, the command for processes, is
+		// indented by its branch
+		branch := ""
+		if r < len(branches) && branches[r] != "" {
+			last := len(cells) - 1
+			branch = branches[r] + " "
+			if n := len([]rune(branch)); n < widths[last] && last < len(row.Cells) {
+				cells[last] = pad(row.Cells[last].String(), widths[last]-n, false)
+			} else {
+				branch = ""
+			}
+		}
+
+		if selected && r == cursor {
+			lines = append(lines, RowSelectedStyle.Render(strings.Join(cells[:len(cells)-1], " ")+" "+branch+cells[len(cells)-1]))
+			continue
+		}
+		lines = append(lines, KeyStyle.Render(cells[0])+" "+
+			ValueStyle.Render(strings.Join(cells[1:len(cells)-1], " "))+" "+
+			TreeStyle.Render(branch)+ValueStyle.Render(cells[len(cells)-1]))
+	}
+
+	return header, lines
+}
+
+// columnWidths returns the width of every column, giving columns without
+// a width whatever the terminal has left
+func (m Model) columnWidths(table *types.Table) []int {
+	remaining := m.contentWidth()
+
+	widths := make([]int, len(table.Columns))
+	flexible := 0
+	for i, col := range table.Columns {
+		widths[i] = col.Width
+		if col.Width == 0 {
+			flexible++
+		}
+		remaining -= col.Width + 1
+	}
+	for i, col := range table.Columns {
+		if col.Width == 0 {
+			widths[i] = max(10, remaining/flexible)
+		}
+	}
+	return widths
+}
+
+// rightAligned reports whether a column holds numbers, judged by its
+// first row
+func rightAligned(table *types.Table, col int) bool {
+	return len(table.Rows) > 0 && col < len(table.Rows[0].Cells) && table.Rows[0].Cells[col].IsNumeric()
+}
+
+// pad fits s into width cells, truncating it with an ellipsis
+func pad(s string, width int, right bool) string {
+	r := []rune(s)
+	if len(r) > width {
+		if width <= 1 {
+			return string(r[:width])
+		}
+		return string(r[:width-1]) + "…"
+	}
+	fill := strings.Repeat(" ", width-len(r))
+	if right {
+		return fill + s
+	}
+	return s + fill
+}
+
+// refreshTable starts a refresh of a table section after an action, so the
+// result shows without waiting for live mode
+func (m Model) refreshTable(index int) tea.Cmd {
+	live, ok := m.Collectors[index].(sysinfo.LiveCollector)
+	if !ok || m.Refreshing[index] {
+		return nil
+	}
+	m.Refreshing[index] = true
+	return refreshCmd(m.Registry, live, index, m.Sections[index])
+}
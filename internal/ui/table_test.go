@@ -0,0 +1,167 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"peekfetch/internal/sysinfo"
+	"peekfetch/internal/types"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func tableModel() Model {
+	row := func(pid, ppid uint64, cpu float64, command string) types.Row {
+		return types.Row{
+			ID:     types.Count(pid).String(),
+			Parent: types.Count(ppid).String(),
+			Cells:  []types.Field{types.Count(pid), types.Percent(cpu), types.Text(command)},
+		}
+	}
+	section := types.Section{
+		Name:     "Processes",
+		Expanded: true,
+		Table: &types.Table{
+			Columns:   []types.Column{{Name: "PID", Width: 5}, {Name: "CPU%", Width: 6, Desc: true}, {Name: "Command"}},
+			Rows:      []types.Row{row(1, 0, 0, "init"), row(20, 1, 12.5, "firefox"), row(30, 40, 50, "rustc"), row(40, 1, 12.5, "fish")},
+			Processes: true,
+		},
+	}
+
+	return Model{
+		Registry:       sysinfo.NewRegistry(),
+		Sections:       []types.Section{section},
+		Loading:        []bool{false},
+		Tables:         make(map[string]*tableView),
+		Width:          80,
+		ViewportHeight: 20,
+	}
+}
+
+// rowIDs returns the IDs of the visible rows of the model's table
+func rowIDs(m Model) []string {
+	section := m.Sections[0]
+	var ids []string
+	for _, row := range m.tableOf(section).rows(section.Table) {
+		ids = append(ids, row.ID)
+	}
+	return ids
+}
+
+func press(m Model, keys ...string) Model {
+	for _, k := range keys {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		switch k {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		case "left":
+			msg = tea.KeyMsg{Type: tea.KeyLeft}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		}
+		updated, _ := m.Update(msg)
+		m = updated.(Model)
+	}
+	return m
+}
+
+func TestTableSortAndFilter(t *testing.T) {
+	m := tableModel()
+
+	// CPU% descending by default, ties by PID
+	if got, want := rowIDs(m), []string{"30", "20", "40", "1"}; !slices.Equal(got, want) {
+		t.Errorf("default order = %v, want %v", got, want)
+	}
+
+	m = press(m, "S")
+	if got, want := rowIDs(m), []string{"1", "20", "40", "30"}; !slices.Equal(got, want) {
+		t.Errorf("reversed order = %v, want %v", got, want)
+	}
+
+	m = press(m, "/", "f", "i", "enter")
+	if got, want := rowIDs(m), []string{"20", "40"}; !slices.Equal(got, want) {
+		t.Errorf("filtered rows = %v, want %v", got, want)
+	}
+
+	m = press(m, "esc")
+	if got := len(rowIDs(m)); got != 4 {
+		t.Errorf("rows after clearing the filter = %d, want 4", got)
+	}
+}
+
+func TestTableCursorFollowsRow(t *testing.T) {
+	m := press(tableModel(), "down")
+	tv := m.tableOf(m.Sections[0])
+	if tv.cursor != "20" {
+		t.Fatalf("cursor = %q, want 20", tv.cursor)
+	}
+
+	// Resorting keeps the same process selected
+	m = press(m, "s")
+	if rows := tv.rows(m.Sections[0].Table); rows[tv.selected(rows)].ID != "20" {
+		t.Errorf("cursor moved off PID 20 after sorting")
+	}
+}
+
+func TestTableSignalNeedsConfirmation(t *testing.T) {
+	m := press(tableModel(), "t")
+	if m.Prompt == nil || m.Prompt.pid != 30 || m.Prompt.name != "SIGTERM" {
+		t.Fatalf("Prompt = %+v, want SIGTERM for PID 30", m.Prompt)
+	}
+
+	m = press(m, "n")
+	if m.Prompt != nil || m.Status != "Cancelled" {
+		t.Errorf("Prompt = %+v, Status = %q after declining", m.Prompt, m.Status)
+	}
+}
+
+func TestTableSignalRefusedBelowRoot(t *testing.T) {
+	m := tableModel()
+	host := sysinfo.LocalHost()
+	host.Root = t.TempDir()
+	m.Registry.SetHost(host)
+
+	m = press(m, "t")
+	if m.Prompt != nil || m.Status == "" {
+		t.Errorf("Prompt = %+v, Status = %q, want no prompt and an error", m.Prompt, m.Status)
+	}
+	if footer := m.View(); strings.Contains(footer, "Signal") || strings.Contains(footer, "Nice") {
+		t.Errorf("footer offers signal or renice below an alternate root")
+	}
+}
+
+func TestTableTree(t *testing.T) {
+	m := press(tableModel(), "T")
+	section := m.Sections[0]
+	tv := m.tableOf(section)
+
+	rows, branches := tv.layout(section.Table)
+	var ids []string
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	if want := []string{"1", "20", "40", "30"}; !slices.Equal(ids, want) {
+		t.Errorf("tree order = %v, want %v", ids, want)
+	}
+	if want := []string{"", "├─", "└─", "   └─"}; !slices.Equal(branches, want) {
+		t.Errorf("branches = %q, want %q", branches, want)
+	}
+
+	// A filtered tree keeps the ancestors of every match
+	m = press(m, "/", "r", "u", "s", "t", "enter")
+	if got, want := rowIDs(m), []string{"1", "40", "30"}; !slices.Equal(got, want) {
+		t.Errorf("filtered tree = %v, want %v", got, want)
+	}
+
+	// Folding a row hides its children
+	m = press(m, "esc", "down", "down", "left")
+	if got, want := rowIDs(m), []string{"1", "20", "40"}; !slices.Equal(got, want) {
+		t.Errorf("folded tree = %v, want %v", got, want)
+	}
+	if _, branches := tv.layout(section.Table); branches[2] != "└─▸" {
+		t.Errorf("folded branch = %q, want %q", branches[2], "└─▸")
+	}
+}
@@ -0,0 +1,232 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Theme is a named color palette for the UI
+type Theme struct {
+	Name       string
+	Primary    lipgloss.TerminalColor
+	Secondary  lipgloss.TerminalColor
+	Success    lipgloss.TerminalColor
+	Warning    lipgloss.TerminalColor
+	Danger     lipgloss.TerminalColor
+	Info       lipgloss.TerminalColor
+	Accent     lipgloss.TerminalColor
+	Muted      lipgloss.TerminalColor
+	Text       lipgloss.TerminalColor
+	Subtle     lipgloss.TerminalColor
+	Background lipgloss.TerminalColor
+	Border     lipgloss.TerminalColor
+	Highlight  lipgloss.TerminalColor // Background of the selected section
+}
+
+// Built-in themes
+var (
+	// DarkTheme is the Tokyo Night palette
+	DarkTheme = Theme{
+		Name:       "dark",
+		Primary:    lipgloss.Color("#7DCFFF"), // Soft blue
+		Secondary:  lipgloss.Color("#BB9AF7"), // Soft purple
+		Success:    lipgloss.Color("#9ECE6A"), // Soft green
+		Warning:    lipgloss.Color("#E0AF68"), // Soft amber
+		Danger:     lipgloss.Color("#F7768E"), // Soft red
+		Info:       lipgloss.Color("#7AA2F7"), // Medium blue
+		Accent:     lipgloss.Color("#73DACA"), // Soft teal
+		Muted:      lipgloss.Color("#565F89"), // Muted blue-gray
+		Text:       lipgloss.Color("#C0CAF5"), // Light periwinkle
+		Subtle:     lipgloss.Color("#9AA5CE"), // Subtle text
+		Background: lipgloss.Color("#1A1B26"), // Deep blue-black
+		Border:     lipgloss.Color("#414868"), // Border gray
+		Highlight:  lipgloss.Color("#292E42"), // Selection gray
+	}
+
+	// LightTheme is the Tokyo Night Day palette, for light terminals
+	LightTheme = Theme{
+		Name:       "light",
+		Primary:    lipgloss.Color("#2E7DE9"),
+		Secondary:  lipgloss.Color("#9854F1"),
+		Success:    lipgloss.Color("#587539"),
+		Warning:    lipgloss.Color("#8C6C3E"),
+		Danger:     lipgloss.Color("#F52A65"),
+		Info:       lipgloss.Color("#2E7DE9"),
+		Accent:     lipgloss.Color("#118C74"),
+		Muted:      lipgloss.Color("#848CB5"),
+		Text:       lipgloss.Color("#3760BF"),
+		Subtle:     lipgloss.Color("#6172B0"),
+		Background: lipgloss.Color("#E1E2E7"),
+		Border:     lipgloss.Color("#A8AECB"),
+		Highlight:  lipgloss.Color("#C4C8DA"),
+	}
+
+	// HighContrastTheme uses saturated colors on black for low-vision users
+	HighContrastTheme = Theme{
+		Name:       "high-contrast",
+		Primary:    lipgloss.Color("#00FFFF"),
+		Secondary:  lipgloss.Color("#FF00FF"),
+		Success:    lipgloss.Color("#00FF00"),
+		Warning:    lipgloss.Color("#FFFF00"),
+		Danger:     lipgloss.Color("#FF3030"),
+		Info:       lipgloss.Color("#5FAFFF"),
+		Accent:     lipgloss.Color("#00FFFF"),
+		Muted:      lipgloss.Color("#BCBCBC"),
+		Text:       lipgloss.Color("#FFFFFF"),
+		Subtle:     lipgloss.Color("#E4E4E4"),
+		Background: lipgloss.Color("#000000"),
+		Border:     lipgloss.Color("#FFFFFF"),
+		Highlight:  lipgloss.Color("#303030"),
+	}
+
+	// ColorblindTheme is built from the Okabe-Ito palette, which stays
+	// distinguishable with the common forms of color vision deficiency
+	ColorblindTheme = Theme{
+		Name:       "colorblind",
+		Primary:    lipgloss.Color("#56B4E9"), // Sky blue
+		Secondary:  lipgloss.Color("#CC79A7"), // Reddish purple
+		Success:    lipgloss.Color("#0072B2"), // Blue
+		Warning:    lipgloss.Color("#E69F00"), // Orange
+		Danger:     lipgloss.Color("#D55E00"), // Vermillion
+		Info:       lipgloss.Color("#F0E442"), // Yellow
+		Accent:     lipgloss.Color("#009E73"), // Bluish green
+		Muted:      lipgloss.Color("#808080"),
+		Text:       lipgloss.Color("#E0E0E0"),
+		Subtle:     lipgloss.Color("#B0B0B0"),
+		Background: lipgloss.Color("#000000"),
+		Border:     lipgloss.Color("#606060"),
+		Highlight:  lipgloss.Color("#2A2A2A"),
+	}
+
+	// MonochromeTheme uses the terminal's default colors only
+	MonochromeTheme = Theme{
+		Name:       "monochrome",
+		Primary:    lipgloss.NoColor{},
+		Secondary:  lipgloss.NoColor{},
+		Success:    lipgloss.NoColor{},
+		Warning:    lipgloss.NoColor{},
+		Danger:     lipgloss.NoColor{},
+		Info:       lipgloss.NoColor{},
+		Accent:     lipgloss.NoColor{},
+		Muted:      lipgloss.NoColor{},
+		Text:       lipgloss.NoColor{},
+		Subtle:     lipgloss.NoColor{},
+		Background: lipgloss.NoColor{},
+		Border:     lipgloss.NoColor{},
+		Highlight:  lipgloss.NoColor{},
+	}
+)
+
+// Themes lists the built-in themes
+var Themes = []Theme{DarkTheme, LightTheme, HighContrastTheme, ColorblindTheme, MonochromeTheme}
+
+// ThemeByName returns the named built-in theme
+func ThemeByName(name string) (Theme, bool) {
+	for _, t := range Themes {
+		if strings.EqualFold(t.Name, name) {
+			return t, true
+		}
+	}
+	return Theme{}, false
+}
+
+// AutoTheme picks the dark or light theme from the terminal background
+func AutoTheme() Theme {
+	if lipgloss.HasDarkBackground() {
+		return DarkTheme
+	}
+	return LightTheme
+}
+
+// UseNoColor follows the NO_COLOR convention: colors are dropped but bold
+// text and borders are kept, so the selected section stays visible
+func UseNoColor() {
+	lipgloss.SetColorProfile(termenv.ANSI)
+	ApplyTheme(MonochromeTheme)
+}
+
+// ForceColor enables colors even when NO_COLOR is set, for a theme the
+// user chose explicitly
+func ForceColor() {
+	lipgloss.SetColorProfile(termenv.ColorProfile())
+}
+
+// ThemeColorNames are the keys a custom theme file may set
+var ThemeColorNames = []string{
+	"primary", "secondary", "success", "warning", "danger", "info", "accent",
+	"muted", "text", "subtle", "background", "border", "highlight",
+}
+
+// WithColors returns a copy of the theme with the given colors replaced.
+// Keys are ThemeColorNames and values are hex colors or ANSI color numbers.
+func (t Theme) WithColors(name string, colors map[string]string) (Theme, error) {
+	t.Name = name
+
+	keys := make([]string, 0, len(colors))
+	for key := range colors {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := colors[key]
+		if !validColor(value) {
+			return t, fmt.Errorf("invalid color %q for %s", value, key)
+		}
+		color := lipgloss.Color(value)
+
+		switch key {
+		case "primary":
+			t.Primary = color
+		case "secondary":
+			t.Secondary = color
+		case "success":
+			t.Success = color
+		case "warning":
+			t.Warning = color
+		case "danger":
+			t.Danger = color
+		case "info":
+			t.Info = color
+		case "accent":
+			t.Accent = color
+		case "muted":
+			t.Muted = color
+		case "text":
+			t.Text = color
+		case "subtle":
+			t.Subtle = color
+		case "background":
+			t.Background = color
+		case "border":
+			t.Border = color
+		case "highlight":
+			t.Highlight = color
+		default:
+			return t, fmt.Errorf("unknown color %q", key)
+		}
+	}
+
+	return t, nil
+}
+
+// validColor accepts #RGB and #RRGGBB hex colors and ANSI numbers 0-255
+func validColor(s string) bool {
+	if strings.HasPrefix(s, "#") {
+		hex := s[1:]
+		if len(hex) != 3 && len(hex) != 6 {
+			return false
+		}
+		return strings.Trim(strings.ToLower(hex), "0123456789abcdef") == ""
+	}
+
+	var n int
+	if _, err := fmt.Sscanf(s, "%d", &n); err != nil || fmt.Sprint(n) != s {
+		return false
+	}
+	return n >= 0 && n <= 255
+}
@@ -2,13 +2,14 @@ package ui
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
+	"time"
 
 	"peekfetch/internal/sysinfo"
 	"peekfetch/internal/types"
 
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -18,18 +19,39 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.Width = msg.Width
 		m.Height = msg.Height
-		// Reserve space for header (6 lines) and footer (3 lines)
-		m.ViewportHeight = msg.Height - 9
+		// Reserve space for header (6 lines and any logo lines beyond the
+		// wordmark) and footer (3 lines)
+		m.ViewportHeight = msg.Height - 9 - max(0, lipgloss.Height(renderBanner(m.Release))-bannerLines)
 		if m.ViewportHeight < 5 {
 			m.ViewportHeight = 5
 		}
 		return m, nil
 
 	case tea.KeyMsg:
+		if m.Prompt != nil {
+			action := *m.Prompt
+			m.Prompt = nil
+			if msg.String() == "y" || msg.String() == "Y" {
+				return m, actionCmd(m.Registry, action)
+			}
+			m.Status = "Cancelled"
+			return m, nil
+		}
+		m.Status = ""
+
+		if m.tableFocused() {
+			if updated, cmd, ok := m.updateTable(msg); ok {
+				return updated, cmd
+			}
+		}
+
 		switch {
 		case key.Matches(msg, key.NewBinding(key.WithKeys("q", "Q", "ctrl+c"))):
 			return m, tea.Quit
 
+		case len(m.Sections) == 0:
+			// Every section is disabled, so there is nothing to navigate
+
 		case key.Matches(msg, key.NewBinding(key.WithKeys("up", "k"))):
 			// If section is expanded and has scroll, scroll up first
 			if m.Sections[m.SelectedIndex].Expanded && m.ScrollOffset > 0 {
@@ -84,43 +106,85 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, key.NewBinding(key.WithKeys("l", "L"))):
 			m.LiveMode = !m.LiveMode
 			if m.LiveMode {
-				return m, tickCmd()
+				return m, tickCmd(m.TickInterval)
 			}
 		}
 
+	case sectionMsg:
+		// Keep the user's expand state from the loading placeholder
+		msg.section.Expanded = m.Sections[msg.index].Expanded
+		m.Sections[msg.index] = msg.section
+		m.Loading[msg.index] = false
+		return m, nil
+
+	case spinner.TickMsg:
+		if !m.isLoading() {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.Spinner, cmd = m.Spinner.Update(msg)
+		return m, cmd
+
+	case refreshMsg:
+		msg.section.Expanded = m.Sections[msg.index].Expanded
+		m.Sections[msg.index] = msg.section
+		m.record(msg.section)
+		m.Refreshing[msg.index] = false
+		return m, m.refreshDetail(msg.index)
+
+	case detailMsg:
+		if tv, ok := m.Tables[msg.table]; ok && tv.detail == msg.id {
+			if msg.err != nil {
+				tv.detail, m.Detail = "", nil
+				m.Status = fmt.Sprintf("Error: %v", msg.err)
+			} else {
+				m.Detail = &msg.section
+			}
+		}
+		return m, nil
+
+	case actionMsg:
+		m.Status = msg.status
+		return m, m.refreshTable(msg.index)
+
 	case tickMsg:
 		if m.LiveMode {
-			// Update CPU section
-			for i := range m.Sections {
-				if m.Sections[i].Name == "CPU" && m.Sections[i].LiveData {
-					if m.Sections[i].Data == nil {
-						m.Sections[i].Data = make(map[string]string)
-					}
-					m.Sections[i].Data["Usage"] = sysinfo.GetCPUUsage()
-				}
-				if m.Sections[i].Name == "Memory" && m.Sections[i].LiveData {
-					updatedSection := sysinfo.GetMemoryInfo()
-					m.Sections[i].Data = updatedSection.Data
+			// Start a background refresh for every live section whose
+			// interval has elapsed and that is not already refreshing
+			now := time.Time(msg)
+			cmds := []tea.Cmd{tickCmd(m.TickInterval)}
+			for i, c := range m.Collectors {
+				live, ok := c.(sysinfo.LiveCollector)
+				if !ok || m.Loading[i] || m.Refreshing[i] || now.Sub(m.LastRefresh[i]) < live.Interval() {
+					continue
 				}
+				m.Refreshing[i] = true
+				m.LastRefresh[i] = now
+				cmds = append(cmds, refreshCmd(m.Registry, live, i, m.Sections[i]))
 			}
-			return m, tickCmd()
+			return m, tea.Batch(cmds...)
 		}
 	}
 
 	return m, nil
 }
 
+// contentWidth is the width expanded section content may take: the
+// terminal's, or 80 columns before it is known, less the content's border
+// and margins
+func (m Model) contentWidth() int {
+	width := m.Width
+	if width <= 0 {
+		width = 80
+	}
+	return width - 8
+}
+
 func (m Model) View() string {
 	var b strings.Builder
 
-	// ASCII Banner
-	banner := `
-   ___           _   ___     _       _     
-  / _ \___ ___ | | _| __|__| |_ ___| |__  
- | (_) / -_) -_)| |/ / _|/ -_)  _/ _| '_ \ 
-  \___/\___\___||___/|___\___\__\__|_| |_|
-`
-	b.WriteString(BannerStyle.Render(banner))
+	// Distribution logo and wordmark
+	b.WriteString("\n" + renderBanner(m.Release) + "\n")
 	b.WriteString("\n")
 
 	// Header with live badge
@@ -144,6 +208,9 @@ func (m Model) View() string {
 		}
 
 		sectionLine := fmt.Sprintf(" %s  %s %s", expandIndicator, icon, section.Name)
+		if m.Loading[i] {
+			sectionLine += " " + m.Spinner.View()
+		}
 
 		if isSelected {
 			sectionLine = SelectedStyle.Render(sectionLine)
@@ -160,10 +227,36 @@ func (m Model) View() string {
 
 			// Get all content lines
 			allLines := m.renderSectionContent(section)
-
-			// Apply scrolling - only show visible lines
 			startLine := m.ScrollOffset
 			endLine := m.ScrollOffset + m.ViewportHeight
+			if m.Loading[i] {
+				allLines = []string{TreeStyle.Render("Loading…")}
+			} else if section.Table != nil {
+				// The table header stays pinned while the rows scroll,
+				// with the cursor row always in view
+				header, rows := m.renderTable(section, true)
+				tv := m.tableOf(section)
+				cursor := tv.selected(tv.rows(section.Table))
+				allLines = rows
+				startLine = tableWindow(m.ScrollOffset, cursor, m.visibleRows(tv))
+				endLine = startLine + m.visibleRows(tv)
+
+				// An open detail pane takes the place of the other rows,
+				// below the row it describes
+				if tv.detail != "" && cursor < len(rows) {
+					header = append(header, rows[cursor])
+					allLines = m.detailLines()
+					startLine = min(tv.detailTop, max(0, len(allLines)-1))
+					endLine = startLine + m.detailHeight(tv)
+				}
+
+				for _, line := range header {
+					content.WriteString(line)
+					content.WriteString("\n")
+				}
+			}
+
+			// Apply scrolling - only show visible lines
 			if endLine > len(allLines) {
 				endLine = len(allLines)
 			}
@@ -175,7 +268,7 @@ func (m Model) View() string {
 				}
 
 				// Add scroll indicators
-				if m.ScrollOffset > 0 {
+				if startLine > 0 {
 					indicator := lipgloss.NewStyle().Foreground(ColorMuted).Render("    ▲ More above (↑ or PgUp)")
 					content.WriteString(indicator + "\n")
 				}
@@ -190,8 +283,34 @@ func (m Model) View() string {
 		}
 	}
 
+	// Pending confirmation or the outcome of the last action
+	if m.Prompt != nil {
+		b.WriteString(StatusStyle.Render(m.Prompt.prompt()))
+		b.WriteString("\n")
+	} else if m.Status != "" {
+		b.WriteString(StatusStyle.Render(m.Status))
+		b.WriteString("\n")
+	}
+
 	// Footer
 	footerText := "↑↓ Navigate/Scroll  │  PgUp/PgDn Fast Scroll  │  ⏎ Expand  │  L Live  │  Q Quit"
+	if m.tableFocused() {
+		footerText = "↑↓ Select  │  ⏎ Details  │  / Filter  │  s/S Sort  │  T Tree  │  t/K Signal  │  +/- Nice"
+		tv := m.tableOf(m.Sections[m.SelectedIndex])
+		if tv.tree {
+			footerText = "↑↓ Select  │  ←→ Fold  │  ⏎ Details  │  / Filter  │  s/S Sort  │  T List  │  t/K Signal"
+		}
+		if tv.detail != "" {
+			footerText = "↑↓ Scroll  │  ⏎/Esc Back  │  t/K Signal  │  +/- Nice  │  Space Collapse  │  Q Quit"
+		}
+		// Processes below an alternate root cannot be acted on
+		if !m.Registry.ControlsProcesses() {
+			footerText = strings.NewReplacer("  │  t/K Signal", "", "  │  +/- Nice", "").Replace(footerText)
+		}
+		if tv.filtering {
+			footerText = "Type to filter  │  ⏎ Keep  │  Esc Clear"
+		}
+	}
 	footer := FooterStyle.Render(footerText)
 	b.WriteString(footer)
 
@@ -201,11 +320,19 @@ func (m Model) View() string {
 // getSectionIcon returns an icon for each section
 func getSectionIcon(name string) string {
 	icons := map[string]string{
-		"System":  "🖥️ ",
-		"CPU":     "⚡",
-		"Memory":  "💾",
-		"Disk":    "💿",
-		"Network": "🌐",
+		"System":    "🖥️ ",
+		"Hardware":  "🔧",
+		"CPU":       "⚡",
+		"GPU":       "🎮",
+		"Displays":  "📺",
+		"Memory":    "💾",
+		"Limits":    "📦",
+		"Disk":      "💿",
+		"Network":   "🌐",
+		"Sensors":   "🌡️ ",
+		"Power":     "🔋",
+		"Packages":  "📚",
+		"Processes": "⚙️ ",
 	}
 	if icon, ok := icons[name]; ok {
 		return icon
@@ -225,64 +352,50 @@ func createProgressBar(percent float64, width int) string {
 	filledWidth := int((percent / 100.0) * float64(width))
 	emptyWidth := width - filledWidth
 
-	var color string
+	var color lipgloss.TerminalColor
 	if percent >= 90 {
-		color = "#F7768E" // Soft red (danger)
+		color = ColorDanger
 	} else if percent >= 75 {
-		color = "#E0AF68" // Soft amber (warning)
+		color = ColorWarning
 	} else if percent >= 50 {
-		color = "#7DCFFF" // Soft blue (info)
+		color = ColorPrimary
 	} else {
-		color = "#9ECE6A" // Soft green (success)
+		color = ColorSuccess
 	}
 
 	filled := strings.Repeat("█", filledWidth)
 	empty := strings.Repeat("░", emptyWidth)
 
-	barStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(color))
-	emptyStyle := lipgloss.NewStyle().Foreground(ColorMuted)
+	barStyle := ProgressBarStyle.Foreground(color)
 
-	return "[" + barStyle.Render(filled) + emptyStyle.Render(empty) + "]"
+	return "[" + barStyle.Render(filled) + ProgressEmptyStyle.Render(empty) + "]"
 }
 
 // countContentLines counts how many lines an expanded section would have
 func (m Model) countContentLines(section types.Section) int {
-	lines := 0
-
-	if section.UseTree {
-		for _, item := range section.TreeData {
-			lines++                  // Item name
-			lines += len(item.Order) // Children
-		}
-	} else {
-		lines = len(section.Order)
-		if lines == 0 {
-			lines = len(section.Data)
-		}
-	}
-
-	return lines
+	return len(m.renderSectionContent(section))
 }
 
 // renderSectionContent renders the section content and returns all lines
 func (m Model) renderSectionContent(section types.Section) []string {
 	allLines := []string{}
 
+	if section.Table != nil {
+		header, rows := m.renderTable(section, true)
+		return append(header, rows...)
+	}
+
 	if section.UseTree {
 		// Tree structure rendering
 		for i, item := range section.TreeData {
 			isLast := i == len(section.TreeData)-1
 
 			// Item name
-			treeBranch := "├─"
-			if isLast {
-				treeBranch = "└─"
-			}
-			allLines = append(allLines, TreeStyle.Render(treeBranch)+" "+KeyStyle.Render(item.Name))
+			allLines = append(allLines, TreeStyle.Render(treeBranch(nil, isLast))+" "+KeyStyle.Render(item.Name))
 
 			// Children
 			maxKeyLen := 0
-			for key := range item.Children {
+			for _, key := range item.Order {
 				if len(key) > maxKeyLen {
 					maxKeyLen = len(key)
 				}
@@ -290,36 +403,24 @@ func (m Model) renderSectionContent(section types.Section) []string {
 
 			for j, key := range item.Order {
 				value := item.Children[key]
-				isLastChild := j == len(item.Order)-1
-
-				childBranch := "│  ├─"
-				if isLast {
-					childBranch = "   ├─"
-				}
-				if isLastChild {
-					if isLast {
-						childBranch = "   └─"
-					} else {
-						childBranch = "│  └─"
-					}
-				}
+				childBranch := treeBranch([]bool{isLast}, j == len(item.Order)-1)
 
 				padding := strings.Repeat(" ", maxKeyLen-len(key))
 
-				// Add progress bar for percentage values
-				if strings.HasSuffix(value, "%") && strings.Contains(key, "Usage") {
-					percentStr := strings.TrimSuffix(value, "%")
-					if percent, err := strconv.ParseFloat(percentStr, 64); err == nil {
-						progressBar := createProgressBar(percent, 18)
-						line := fmt.Sprintf("%s %s%s %s %s",
-							TreeStyle.Render(childBranch),
-							SubKeyStyle.Render(key),
-							padding,
-							progressBar,
-							ValueStyle.Render(value))
-						allLines = append(allLines, line)
-						continue
+				// Add progress bar for bounded values
+				if percent, ok := value.Ratio(); ok {
+					progressBar := createProgressBar(percent, 18)
+					line := fmt.Sprintf("%s %s%s %s %s",
+						TreeStyle.Render(childBranch),
+						SubKeyStyle.Render(key),
+						padding,
+						progressBar,
+						ValueStyle.Render(value.String()))
+					if history := m.renderHistory(section.Name, item.Name, key, value); history != "" {
+						line += "  " + history
 					}
+					allLines = append(allLines, line)
+					continue
 				}
 
 				line := fmt.Sprintf("%s %s%s %s %s",
@@ -327,19 +428,15 @@ func (m Model) renderSectionContent(section types.Section) []string {
 					SubKeyStyle.Render(key),
 					padding,
 					TreeStyle.Render("│"),
-					ValueStyle.Render(value))
+					ValueStyle.Render(value.String()))
+				if history := m.renderRateHistory(section.Name, item.Name, key, value); history != "" {
+					line += history
+				}
 				allLines = append(allLines, line)
 			}
 		}
 	} else {
 		// Regular key-value rendering
-		maxKeyLen := 0
-		for key := range section.Data {
-			if len(key) > maxKeyLen {
-				maxKeyLen = len(key)
-			}
-		}
-
 		keys := section.Order
 		if len(keys) == 0 {
 			keys = []string{}
@@ -348,37 +445,151 @@ func (m Model) renderSectionContent(section types.Section) []string {
 			}
 		}
 
+		// Keys drawn in a grid are rendered together where the first of
+		// them appears
+		gridOf := make(map[string]int)
+		for i, grid := range section.Grids {
+			for _, key := range grid {
+				gridOf[key] = i
+			}
+		}
+		drawn := make(map[int]bool)
+
+		maxKeyLen := 0
+		for _, key := range keys {
+			if _, ok := gridOf[key]; !ok && len(key) > maxKeyLen {
+				maxKeyLen = len(key)
+			}
+		}
+
 		for _, key := range keys {
 			value, ok := section.Data[key]
 			if !ok {
 				continue
 			}
 
+			if grid, ok := gridOf[key]; ok {
+				if !drawn[grid] {
+					drawn[grid] = true
+					allLines = append(allLines, m.renderGrid(keys, gridOf, grid, section.Data)...)
+				}
+				continue
+			}
+
 			padding := strings.Repeat(" ", maxKeyLen-len(key))
 
-			// Add progress bar for percentage values
-			if strings.HasSuffix(value, "%") && strings.Contains(key, "Usage") {
-				percentStr := strings.TrimSuffix(value, "%")
-				if percent, err := strconv.ParseFloat(percentStr, 64); err == nil {
-					progressBar := createProgressBar(percent, 20)
-					line := fmt.Sprintf("%s%s %s %s",
-						KeyStyle.Render(key),
-						padding,
-						progressBar,
-						ValueStyle.Render(value))
-					allLines = append(allLines, line)
-					continue
+			// Add progress bar for bounded values
+			if percent, ok := value.Ratio(); ok {
+				progressBar := createProgressBar(percent, 20)
+				line := fmt.Sprintf("%s%s %s %s",
+					KeyStyle.Render(key),
+					padding,
+					progressBar,
+					ValueStyle.Render(value.String()))
+				if history := m.renderHistory(section.Name, "", key, value); history != "" {
+					line += "  " + history
 				}
+				allLines = append(allLines, line)
+				continue
 			}
 
 			line := fmt.Sprintf("%s%s %s %s",
 				KeyStyle.Render(key),
 				padding,
 				KeyStyle.Render("│"),
-				ValueStyle.Render(value))
+				ValueStyle.Render(value.String()))
+			if history := m.renderRateHistory(section.Name, "", key, value); history != "" {
+				line += history
+			}
 			allLines = append(allLines, line)
 		}
 	}
 
+	// Nothing was found, such as sensors in a VM
+	if len(allLines) == 0 {
+		return []string{TreeStyle.Render("Not available")}
+	}
 	return allLines
 }
+
+// treeBranch returns the branch drawn before a tree entry. parents holds,
+// for each enclosing level, whether that ancestor was the last of its
+// siblings, which decides whether its vertical line continues.
+func treeBranch(parents []bool, isLast bool) string {
+	var b strings.Builder
+	for _, last := range parents {
+		if last {
+			b.WriteString("   ")
+		} else {
+			b.WriteString("│  ")
+		}
+	}
+	if isLast {
+		b.WriteString("└─")
+	} else {
+		b.WriteString("├─")
+	}
+	return b.String()
+}
+
+// renderGrid draws the bounded fields of one grid, such as per-CPU usage,
+// as compact progress bars in as many columns as fit the terminal width.
+// Cells run down each column first, like htop's CPU meters.
+func (m Model) renderGrid(keys []string, gridOf map[string]int, grid int, data map[string]types.Field) []string {
+	members := []string{}
+	maxKeyLen := 0
+	for _, key := range keys {
+		if g, ok := gridOf[key]; !ok || g != grid {
+			continue
+		}
+		if _, ok := data[key]; !ok {
+			continue
+		}
+		members = append(members, key)
+		if len(key) > maxKeyLen {
+			maxKeyLen = len(key)
+		}
+	}
+
+	const barWidth = 10
+	const valueWidth = 6 // "100.0%"
+	const gap = 3
+
+	// Key, bar with brackets and value, separated by spaces
+	cellWidth := maxKeyLen + 1 + barWidth + 2 + 1 + valueWidth
+
+	columns := (m.contentWidth() + gap) / (cellWidth + gap)
+	if columns < 1 {
+		columns = 1
+	}
+	if columns > len(members) {
+		columns = len(members)
+	}
+	rows := (len(members) + columns - 1) / columns
+
+	lines := make([]string, 0, rows)
+	for row := 0; row < rows; row++ {
+		var line strings.Builder
+		for col := 0; col < columns; col++ {
+			i := col*rows + row
+			if i >= len(members) {
+				break
+			}
+			if col > 0 {
+				line.WriteString(strings.Repeat(" ", gap))
+			}
+
+			key := members[i]
+			value := data[key]
+			percent, _ := value.Ratio()
+			text := value.String()
+			line.WriteString(KeyStyle.Render(key))
+			line.WriteString(strings.Repeat(" ", maxKeyLen-len(key)+1))
+			line.WriteString(createProgressBar(percent, barWidth))
+			line.WriteString(" ")
+			line.WriteString(ValueStyle.Render(strings.Repeat(" ", max(0, valueWidth-len(text))) + text))
+		}
+		lines = append(lines, line.String())
+	}
+	return lines
+}
@@ -0,0 +1,91 @@
+package ui
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"peekfetch/internal/sysinfo"
+	"peekfetch/internal/sysinfo/sysinfotest"
+	"peekfetch/internal/types"
+)
+
+// fixtures is the sysinfo fixture corpus, relative to this package
+var fixtures = filepath.Join("..", "sysinfo", sysinfotest.Dir)
+
+func TestMain(m *testing.M) {
+	// Golden output must not depend on the terminal or the time zone
+	UsePlainText()
+	time.Local = time.UTC
+	os.Exit(m.Run())
+}
+
+func TestViewGolden(t *testing.T) {
+	names, err := sysinfotest.Names(fixtures)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			host, err := sysinfotest.Load(filepath.Join(fixtures, name))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			registry := sysinfo.DefaultRegistry()
+			registry.SetHost(host)
+
+			m := InitialModel(registry, Options{})
+			m.ViewportHeight = 100
+			for i, section := range registry.CollectAll(context.Background()) {
+				m.Sections[i] = section
+				m.Loading[i] = false
+			}
+
+			// Render the view once per section, with that section
+			// selected and expanded
+			var b strings.Builder
+			for i := range m.Sections {
+				view := m
+				view.Sections = append(view.Sections[:0:0], m.Sections...)
+				view.SelectedIndex = i
+				view.Sections[i].Expanded = true
+				b.WriteString(view.View())
+				b.WriteString("\n")
+			}
+
+			sysinfotest.Golden(t, filepath.Join("testdata", name+".golden"), []byte(b.String()))
+		})
+	}
+}
+
+func TestNoSections(t *testing.T) {
+	// A configuration may disable every section
+	registry := sysinfo.NewRegistry()
+	m := InitialModel(registry, Options{})
+	m = press(m, "down", "k", "enter", "pagedown")
+	if !strings.Contains(m.View(), "Q Quit") {
+		t.Errorf("View without sections lacks the footer")
+	}
+}
+
+func TestRenderOnce(t *testing.T) {
+	table := tableModel().Sections[0]
+	out := RenderOnce([]types.Section{{Name: "Sensors", UseTree: true}, table}, sysinfo.OSRelease{})
+
+	for _, line := range strings.Split(out, "\n") {
+		if strings.TrimSpace(line) == "│" {
+			t.Errorf("Section rendered as a lone border:\n%s", out)
+		}
+	}
+	if !strings.Contains(out, "Not available") {
+		t.Errorf("Empty section lacks \"Not available\":\n%s", out)
+	}
+	if n := strings.Count(out, "Command"); n != 1 {
+		t.Errorf("Table header rendered %d times, want once:\n%s", n, out)
+	}
+}